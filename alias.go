@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	CLITopics = append(CLITopics, &Topic{
+		Name:        "alias",
+		Description: "manage your own command aliases",
+		Commands: Commands{
+			{
+				Topic:            "alias",
+				Command:          "set",
+				Description:      "create or change an alias",
+				Args:             []Arg{{Name: "name"}, {Name: "command"}},
+				VariableArgs:     true,
+				FlagsBeforeArgs:  true,
+				DisableAnalytics: true,
+				Help: `The alias runs the command with any extra arguments added to the end.
+Give the command as separate arguments or as one quoted string.
+Aliases are stored in config.json.
+
+Examples:
+
+  $ heroku alias:set deploy-logs logs --tail --source app
+  $ heroku deploy-logs -a myapp
+  $ heroku alias:set greet 'run "echo hello world"'`,
+				Run: aliasSet,
+			},
+			{
+				Topic:            "alias",
+				Command:          "list",
+				Description:      "list your aliases",
+				DisableAnalytics: true,
+				Run:              aliasList,
+			},
+			{
+				Topic:            "alias",
+				Command:          "remove",
+				Description:      "remove an alias",
+				Args:             []Arg{{Name: "name"}},
+				DisableAnalytics: true,
+				Run:              aliasRemove,
+			},
+		},
+	})
+}
+
+func aliasSet(ctx *Context) {
+	args := ctx.Args.([]string)
+	if len(args) < 2 {
+		ExitWithMessage("Usage: heroku alias:set NAME COMMAND")
+		return
+	}
+	name, expansion := args[0], args[1:]
+	if len(expansion) == 1 {
+		words, err := splitShellWords(expansion[0])
+		if err != nil {
+			ExitWithMessage("%s", err)
+			return
+		}
+		expansion = words
+	}
+	if len(expansion) == 0 {
+		ExitWithMessage("Usage: heroku alias:set NAME COMMAND")
+		return
+	}
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " \t") {
+		ExitWithMessage("%s is not a valid alias name.", name)
+		return
+	}
+	if conflict := aliasConflict(name); conflict != nil {
+		ExitWithMessage("%s is already the heroku command %s.", yellow(name), cyan(conflict.String()))
+		return
+	}
+	if findCommand(expansion[0]) == nil {
+		ExitWithMessage("%s is not a heroku command.", yellow(expansion[0]))
+		return
+	}
+	if config.Aliases == nil {
+		config.Aliases = map[string]string{}
+	}
+	config.Aliases[name] = joinShellWords(expansion)
+	must(saveJSON(config, configPath()))
+	Printf("heroku %s now runs heroku %s\n", cyan(name), cyan(config.Aliases[name]))
+}
+
+func aliasList(ctx *Context) {
+	if len(config.Aliases) == 0 {
+		Println("You have no aliases. Create one with heroku alias:set.")
+		return
+	}
+	names := make([]string, 0, len(config.Aliases))
+	for name := range config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line := name + " = " + config.Aliases[name]
+		if conflict := aliasConflict(name); conflict != nil {
+			line += yellow(" (not used, heroku " + name + " runs " + conflict.String() + ")")
+		}
+		Println(line)
+	}
+}
+
+func aliasRemove(ctx *Context) {
+	name := ctx.Args.(map[string]string)["name"]
+	if _, ok := config.Aliases[name]; !ok {
+		ExitWithMessage("%s is not an alias.", yellow(name))
+		return
+	}
+	delete(config.Aliases, name)
+	must(saveJSON(config, configPath()))
+	Printf("Removed alias %s\n", cyan(name))
+}
+
+// aliasConflict is the command that would run instead of the alias name
+func aliasConflict(name string) *Command {
+	if command := findCommand(name); command != nil {
+		return command
+	}
+	if topic := AllTopics().ByName(name); topic != nil {
+		return &Command{Topic: topic.Name}
+	}
+	return nil
+}
+
+// expandUserAlias replaces a user alias at the start of args with the command it stands for.
+// Commands always take precedence over aliases with the same name.
+func expandUserAlias(args []string) []string {
+	if len(args) == 0 || config == nil {
+		return args
+	}
+	expansion, ok := config.Aliases[args[0]]
+	if !ok || aliasConflict(args[0]) != nil {
+		return args
+	}
+	words, err := splitShellWords(expansion)
+	if err != nil {
+		Warn(fmt.Sprintf("alias %s: %s", args[0], err))
+		return args
+	}
+	return append(words, args[1:]...)
+}
+
+var plainShellWordRegexp = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// joinShellWords joins words into a line splitShellWords splits back into the same words,
+// single quoting the words that need it
+func joinShellWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if plainShellWordRegexp.MatchString(word) {
+			quoted[i] = word
+		} else {
+			quoted[i] = "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
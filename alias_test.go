@@ -0,0 +1,86 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("alias", func() {
+	var configHome string
+	BeforeEach(func() {
+		configHome = cli.ConfigHome
+		cli.ConfigHome = filepath.Join("tmp", "config")
+		os.MkdirAll(cli.ConfigHome, 0755)
+		cli.Start("heroku", "alias:set", "v", "version")
+	})
+	AfterEach(func() {
+		cli.Start("heroku", "alias:remove", "v")
+		os.RemoveAll(cli.ConfigHome)
+		cli.ConfigHome = configHome
+	})
+
+	It("runs the aliased command", func() {
+		Expect(stdout()).To(Equal("heroku v now runs heroku version\n"))
+		cli.Start("heroku", "v")
+		Expect(stdout()).To(ContainSubstring("heroku-cli/" + cli.Version))
+	})
+
+	It("lists aliases", func() {
+		cli.Start("heroku", "alias:list")
+		Expect(stdout()).To(HaveSuffix("v = version\n"))
+	})
+
+	It("does not replace commands", func() {
+		cli.Start("heroku", "alias:set", "login", "version")
+		Expect(stderr()).To(Equal(" !    login is already the heroku command auth:login.\n"))
+	})
+
+	Context("with arguments", func() {
+		var topicBackup cli.Topics
+		var echoed []string
+		BeforeEach(func() {
+			echoed = nil
+			topicBackup = cli.CLITopics
+			cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+				Name: "echo",
+				Commands: cli.Commands{
+					{Topic: "echo", VariableArgs: true, Run: func(ctx *cli.Context) { echoed = ctx.Args.([]string) }},
+				},
+			})
+		})
+		AfterEach(func() {
+			cli.Start("heroku", "alias:remove", "say")
+			cli.CLITopics = topicBackup
+		})
+
+		It("keeps quoted arguments together", func() {
+			cli.Start("heroku", "alias:set", "say", "echo", "hello world", "it's")
+			cli.Start("heroku", "alias:list")
+			Expect(stdout()).To(ContainSubstring("say = echo 'hello world' 'it'\\''s'\n"))
+			cli.Start("heroku", "say", "again")
+			Expect(echoed).To(Equal([]string{"hello world", "it's", "again"}))
+		})
+
+		It("splits a command given as one string like a shell", func() {
+			cli.Start("heroku", "alias:set", "say", `echo "hello world"`)
+			cli.Start("heroku", "say")
+			Expect(echoed).To(Equal([]string{"hello world"}))
+		})
+
+		It("keeps the flags of the command", func() {
+			cli.Start("heroku", "alias:set", "say", "echo", "--verbose", "--no-color", "--dry-run", "-h")
+			Expect(stdout()).To(ContainSubstring("heroku say now runs heroku echo --verbose --no-color --dry-run -h\n"))
+		})
+	})
+
+	It("removes aliases", func() {
+		cli.Start("heroku", "alias:remove", "v")
+		cli.Start("heroku", "alias:list")
+		Expect(stdout()).To(HaveSuffix("You have no aliases. Create one with heroku alias:set.\n"))
+	})
+})
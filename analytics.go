@@ -30,17 +30,19 @@ var currentAnalyticsCommand = &AnalyticsCommand{
 
 // AnalyticsCommand represents an analytics command
 type AnalyticsCommand struct {
-	Command       string `json:"command"`
-	Plugin        string `json:"plugin,omitempty"`
-	PluginVersion string `json:"plugin_version,omitempty"`
-	Timestamp     int64  `json:"timestamp"`
-	Version       string `json:"version"`
-	OS            string `json:"os"`
-	Arch          string `json:"arch"`
-	Language      string `json:"language"`
-	Status        int    `json:"status"`
-	Runtime       int64  `json:"runtime"`
-	Valid         bool   `json:"valid"`
+	Command       string   `json:"command"`
+	Plugin        string   `json:"plugin,omitempty"`
+	PluginVersion string   `json:"plugin_version,omitempty"`
+	Timestamp     int64    `json:"timestamp"`
+	Version       string   `json:"version"`
+	OS            string   `json:"os"`
+	Arch          string   `json:"arch"`
+	Language      string   `json:"language"`
+	Status        int      `json:"status"`
+	Runtime       int64    `json:"runtime"`
+	Valid         bool     `json:"valid"`
+	Deprecated    []string `json:"deprecated,omitempty"`
+	Confirmation  string   `json:"confirmation,omitempty"`
 	start         time.Time
 }
 
@@ -50,6 +52,22 @@ func (c *AnalyticsCommand) RecordStart() {
 	c.start = time.Now()
 }
 
+// RecordDeprecation records that a deprecated command or flag was used
+func (c *AnalyticsCommand) RecordDeprecation(name string) {
+	if c == nil {
+		return
+	}
+	c.Deprecated = append(c.Deprecated, name)
+}
+
+// RecordConfirmation records how a Dangerous command was confirmed: flag, prompt or refused
+func (c *AnalyticsCommand) RecordConfirmation(result string) {
+	if c == nil {
+		return
+	}
+	c.Confirmation = result
+}
+
 // RecordEnd marks when a command was completed
 // and records it to the analytics file
 func (c *AnalyticsCommand) RecordEnd(status int) {
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// unexpandedArgs are the arguments the CLI was started with before argument files were expanded.
+// Commands run again in other processes use them so the arguments are not shown in process listings.
+var unexpandedArgs []string
+
+// argsFromStdin is what @- read from stdin
+var argsFromStdin []byte
+
+// expandArgFiles replaces @FILE arguments with the arguments in FILE and @- with the arguments on stdin.
+// Start expands the arguments of every command before looking the command up.
+// Each line is split into arguments with shell-like quoting. Blank lines and lines starting with # are skipped.
+// Arguments after -- are not expanded and @@ is a literal @.
+func expandArgFiles(args []string) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	argsFromStdin = nil
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return append(expanded, args[i:]...), nil
+		case strings.HasPrefix(arg, "@@"):
+			expanded = append(expanded, arg[1:])
+		case arg == "@-":
+			if argsFromStdin != nil {
+				return nil, fmt.Errorf("@- can only be used once")
+			}
+			b, err := ioutil.ReadAll(Stdin)
+			if err != nil {
+				return nil, fmt.Errorf("Could not read arguments from stdin: %s", err)
+			}
+			argsFromStdin = b
+			fromStdin, err := splitArgLines(string(b), "stdin")
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, fromStdin...)
+		case len(arg) > 1 && strings.HasPrefix(arg, "@"):
+			fromFile, err := readArgsFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, fromFile...)
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+	return expanded, nil
+}
+
+func readArgsFile(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read arguments from %s: %s\nUse @@%s to pass @%s as it is.", path, err, path, path)
+	}
+	return splitArgLines(string(b), path)
+}
+
+func splitArgLines(s, name string) ([]string, error) {
+	args := []string{}
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words, err := splitShellWords(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", name, i+1, err)
+		}
+		args = append(args, words...)
+	}
+	return args, nil
+}
+
+// splitShellWords splits a line into words the way a shell would.
+// Single quotes keep everything literally, double quotes allow \" and \\,
+// and a backslash outside quotes escapes the next character.
+func splitShellWords(line string) ([]string, error) {
+	words := []string{}
+	var word []rune
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			word = append(word, c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				word = append(word, c)
+			}
+		case quote == '"':
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				word = append(word, c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\':
+			escaped = true
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\r':
+			if inWord {
+				words = append(words, string(word))
+				word = nil
+				inWord = false
+			}
+		default:
+			word = append(word, c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("missing closing %c", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("line ends with \\")
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
@@ -0,0 +1,86 @@
+package main_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("argument files", func() {
+	var topicBackup cli.Topics
+	var args interface{}
+	var flags map[string]interface{}
+	argsFile := filepath.Join("tmp", "args.txt")
+	BeforeEach(func() {
+		topicBackup = cli.CLITopics
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name: "echo",
+			Commands: cli.Commands{
+				{
+					Topic:        "echo",
+					VariableArgs: true,
+					Flags:        cli.Flags{{Name: "name", HasValue: true}},
+					Run: func(ctx *cli.Context) {
+						args = ctx.Args
+						flags = ctx.Flags
+					},
+				},
+			},
+		})
+		os.MkdirAll(filepath.Dir(argsFile), 0755)
+		ioutil.WriteFile(argsFile, []byte("--name \"big app\"\n# a comment\n\nKEY='it''s' OTHER=a\\ b\n"), 0644)
+	})
+	AfterEach(func() {
+		cli.CLITopics = topicBackup
+		os.Remove(argsFile)
+	})
+
+	It("expands arguments from a file", func() {
+		cli.Start("heroku", "echo", "@"+argsFile, "last")
+		Expect(flags["name"]).To(Equal("big app"))
+		Expect(args).To(Equal([]string{"KEY=its", "OTHER=a b", "last"}))
+	})
+
+	It("expands arguments from stdin", func() {
+		cli.Stdin = bufio.NewReader(strings.NewReader("FOO=bar\nBAZ=\"1 2\"\n"))
+		cli.Start("heroku", "echo", "@-")
+		Expect(args).To(Equal([]string{"FOO=bar", "BAZ=1 2"}))
+	})
+
+	It("keeps @@ and arguments after -- literal", func() {
+		cli.Start("heroku", "echo", "@@handle", "--", "@"+argsFile)
+		Expect(args).To(Equal([]string{"@handle", "@" + argsFile}))
+	})
+
+	It("keeps scoped npm packages given with @@ literal", func() {
+		cli.Start("heroku", "echo", "@@acme/heroku-tools@1.2.0")
+		Expect(args).To(Equal([]string{"@acme/heroku-tools@1.2.0"}))
+	})
+
+	It("expands arguments for built-in commands", func() {
+		configHome := cli.ConfigHome
+		cli.ConfigHome = filepath.Join("tmp", "config")
+		os.MkdirAll(cli.ConfigHome, 0755)
+		defer func() {
+			cli.Start("heroku", "alias:remove", "greet")
+			os.RemoveAll(cli.ConfigHome)
+			cli.ConfigHome = configHome
+		}()
+		cli.Start("heroku", "alias:set", "greet", "echo", "@"+argsFile)
+		cli.Start("heroku", "alias:list")
+		Expect(stdout()).To(HaveSuffix("greet = echo --name 'big app' KEY=its 'OTHER=a b'\n"))
+	})
+
+	It("shows an error for unreadable files", func() {
+		cli.Start("heroku", "echo", "@tmp/missing.txt")
+		Expect(stderr()).To(HavePrefix(" !    Could not read arguments from tmp/missing.txt"))
+		Expect(stderr()).To(ContainSubstring("Use @@tmp/missing.txt to pass @tmp/missing.txt as it is."))
+	})
+})
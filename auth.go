@@ -14,12 +14,13 @@ import (
 )
 
 func init() {
-	CLITopics = append(CLITopics, Topics{{
+	CLITopics = append(CLITopics, &Topic{
 		Name:        "auth",
 		Description: "authentication (login/logout)",
 		Commands: []*Command{
 			{
 				Command:     "login",
+				Aliases:     []string{"login"},
 				Description: "login with your Heroku credentials.",
 				Flags: []Flag{
 					{Name: "sso", Description: "login for enterprise users under SSO"},
@@ -28,11 +29,13 @@ func init() {
 			},
 			{
 				Command:     "logout",
+				Aliases:     []string{"logout"},
 				Description: "clear your local Heroku credentials",
 				Run:         logout,
 			},
 			{
 				Command:     "whoami",
+				Aliases:     []string{"whoami"},
 				Description: "display your Heroku login",
 				Help: `Example:
 
@@ -57,123 +60,34 @@ func init() {
 			},
 			{
 				Command:     "2fa",
+				Aliases:     []string{"2fa", "twofactor"},
 				Description: "check 2fa status",
 				NeedsAuth:   true,
 				Run:         twoFactorRun,
 			},
 			{
 				Command:     "2fa:enable",
+				Aliases:     []string{"2fa:enable", "twofactor:enable"},
 				Description: "enable 2fa on your account",
 				NeedsAuth:   true,
 				Run:         twoFactorEnableRun,
 			},
 			{
 				Command:     "2fa:generate",
+				Aliases:     []string{"2fa:generate", "2fa:generate-recovery-codes", "twofactor:generate-recovery-codes"},
 				Description: "generates and replaces recovery codes",
 				NeedsAuth:   true,
 				Run:         twoFactorGenerateRun,
 			},
 			{
 				Command:     "2fa:disable",
+				Aliases:     []string{"2fa:disable", "twofactor:disable"},
 				Description: "disable two-factor authentication for your account",
 				NeedsAuth:   true,
 				Run:         twoFactorDisableRun,
 			},
 		},
-	},
-		{
-			Name:   "whoami",
-			Hidden: true,
-			Commands: []*Command{
-				{
-					Description: "display your Heroku login",
-					Help: `Example:
-
-  $ heroku auth:whoami
-	email@example.com
-
-	whoami will return nonzero status if not logged in:
-
-  $ heroku auth:whoami
-	not logged in
-	$ echo $?
-	100`,
-					Run: whoami,
-				},
-			},
-		},
-		{
-			Name:        "login",
-			Description: "login with your Heroku credentials.",
-			Commands: []*Command{
-				{
-					Description: "login with your Heroku credentials.",
-					Flags: []Flag{
-						{Name: "sso", Description: "login for enterprise users under SSO"},
-					},
-					Run: login,
-				},
-			},
-		},
-		{
-			Name:        "logout",
-			Hidden:      true,
-			Description: "clear your local Heroku credentials",
-			Commands: []*Command{
-				{
-					Description: "clear your local Heroku credentials",
-					Run:         logout,
-				},
-			},
-		},
-		{
-			Name:   "twofactor",
-			Hidden: true,
-			Commands: Commands{
-				{
-					NeedsAuth:   true,
-					Description: "check 2fa status",
-					Run:         twoFactorRun,
-				},
-				{
-					Command:     "generate-recovery-codes",
-					Description: "Generates and replaces recovery codes",
-					NeedsAuth:   true,
-					Run:         twoFactorGenerateRun,
-				},
-				{
-					Command:     "disable",
-					Description: "Disable two-factor authentication for your account",
-					NeedsAuth:   true,
-					Run:         twoFactorDisableRun,
-				},
-			},
-		},
-		{
-			Name:   "2fa",
-			Hidden: true,
-			Commands: Commands{
-				{
-					NeedsAuth:   true,
-					Description: "check 2fa status",
-					Run:         twoFactorRun,
-				},
-				{
-					Command:     "generate-recovery-codes",
-					Description: "Generates and replaces recovery codes",
-					NeedsAuth:   true,
-					Run:         twoFactorGenerateRun,
-				},
-				{
-					Command:     "disable",
-					Description: "Disable two-factor authentication for your account",
-					NeedsAuth:   true,
-					Run:         twoFactorDisableRun,
-				},
-			},
-		},
-	}...,
-	)
+	})
 }
 
 func whoami(ctx *Context) {
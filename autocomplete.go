@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	CLITopics = append(CLITopics, &Topic{
+		Name:        "autocomplete",
+		Description: "generate shell completion scripts",
+		Commands: Commands{
+			{
+				Topic:            "autocomplete",
+				Description:      "generate a bash or zsh completion script",
+				Args:             []Arg{{Name: "shell"}},
+				DisableAnalytics: true,
+				Help: `Completes topics, commands, flags and flag values.
+Regenerate the script after installing or updating plugins.
+
+Example:
+
+  $ heroku autocomplete bash > /etc/bash_completion.d/heroku
+  $ heroku autocomplete zsh > ~/.zsh/completion/_heroku`,
+				Run: autocompleteRun,
+			},
+			{
+				Topic:            "autocomplete",
+				Command:          "values",
+				Description:      "list the completion values for a command line",
+				Hidden:           true,
+				VariableArgs:     true,
+				DisableAnalytics: true,
+				Run:              autocompleteValuesRun,
+			},
+		},
+	})
+}
+
+func autocompleteRun(ctx *Context) {
+	commands := completableCommands()
+	switch shell := ctx.Args.(map[string]string)["shell"]; shell {
+	case "bash":
+		Print(bashCompletion(commands))
+	case "zsh":
+		Print(zshCompletion(commands))
+	default:
+		ExitWithMessage("Unsupported shell %s. Use bash or zsh.", shell)
+	}
+}
+
+func autocompleteValuesRun(ctx *Context) {
+	args := expandUserAlias(ctx.Args.([]string))
+	if len(args) == 0 {
+		return
+	}
+	command := findCommand(args[0])
+	if command == nil {
+		return
+	}
+	for _, value := range completionValues(command, args[1:]) {
+		Println(value)
+	}
+}
+
+// hasDynamicCompletion is true if any of the command's flags or args use a Completer
+func hasDynamicCompletion(command *Command) bool {
+	for _, flag := range command.allFlags() {
+		if flag.Completion != "" {
+			return true
+		}
+	}
+	for _, arg := range command.Args {
+		if arg.Completion != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// completableCommands is every non-hidden command keyed by each name it can be run with
+func completableCommands() map[string]*Command {
+	commands := map[string]*Command{}
+	for _, command := range AllCommands().NonHidden() {
+		if _, ok := commands[command.String()]; !ok {
+			commands[command.String()] = command
+		}
+		for _, alias := range command.Aliases {
+			if _, ok := commands[alias]; !ok {
+				commands[alias] = command
+			}
+		}
+	}
+	for _, topic := range AllTopics().NonHidden() {
+		if _, ok := commands[topic.Name]; !ok {
+			commands[topic.Name] = &Command{Topic: topic.Name, Description: topic.Description}
+		}
+	}
+	for name := range config.Aliases {
+		if _, ok := commands[name]; !ok {
+			if command := findCommand(expandUserAlias([]string{name})[0]); command != nil {
+				commands[name] = command
+			}
+		}
+	}
+	return commands
+}
+
+func sortedCommandNames(commands map[string]*Command) []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func completionFlagNames(flag Flag) []string {
+	names := []string{}
+	if flag.Name != "" {
+		names = append(names, "--"+flag.Name)
+	}
+	if flag.Char != "" {
+		names = append(names, "-"+flag.Char)
+	}
+	return names
+}
+
+func bashCompletion(commands map[string]*Command) string {
+	names := sortedCommandNames(commands)
+	var flags, values, dynamicValues bytes.Buffer
+	dynamic := []string{}
+	for _, name := range names {
+		if hasDynamicCompletion(commands[name]) {
+			dynamic = append(dynamic, name)
+		}
+		cmdFlags := []string{}
+		for _, flag := range commands[name].allFlags() {
+			if flag.Hidden {
+				continue
+			}
+			cmdFlags = append(cmdFlags, completionFlagNames(flag)...)
+			if flag.Type == FlagEnum {
+				patterns := []string{}
+				for _, f := range completionFlagNames(flag) {
+					patterns = append(patterns, fmt.Sprintf(`"%s %s"`, name, f))
+				}
+				fmt.Fprintf(&values, "      %s) words=%q ;;\n", strings.Join(patterns, "|"), strings.Join(flag.Options, " "))
+			}
+		}
+		if len(cmdFlags) > 0 {
+			fmt.Fprintf(&flags, "        %s) words=%q ;;\n", name, strings.Join(cmdFlags, " "))
+		}
+	}
+	if len(dynamic) > 0 {
+		fmt.Fprintf(&dynamicValues, `    if [[ -z "$words" && "$cur" != -* ]]; then
+      case "$cmd" in
+        %s) words="$(heroku autocomplete:values -- "${args[@]:1}" 2>/dev/null)" ;;
+      esac
+    fi
+`, strings.Join(dynamic, "|"))
+	}
+	return fmt.Sprintf(`# heroku bash completion
+# generated by "heroku autocomplete bash", regenerate it after installing plugins
+
+_heroku()
+{
+  local line="${COMP_LINE:0:$COMP_POINT}"
+  local args=($line)
+  [[ "$line" == *" " ]] && args+=("")
+  local cur="${args[${#args[@]}-1]}" prev="${args[${#args[@]}-2]}" cmd="${args[1]}" words=""
+
+  if [[ ${#args[@]} -eq 2 ]]; then
+    words=%q
+  else
+    case "$cmd $prev" in
+%s    esac
+    if [[ -z "$words" && "$cur" == -* ]]; then
+      case "$cmd" in
+%s      esac
+    fi
+%s  fi
+
+  COMPREPLY=($(compgen -W "$words" -- "$cur"))
+  # bash splits words on colons so only complete the part after the last one
+  if [[ "$cur" == *:* ]]; then
+    local colon_prefix="${cur%%"${cur##*:}"}"
+    COMPREPLY=("${COMPREPLY[@]#"$colon_prefix"}")
+  fi
+}
+
+complete -o default -F _heroku heroku
+`, strings.Join(names, " "), values.String(), flags.String(), dynamicValues.String())
+}
+
+func zshCompletion(commands map[string]*Command) string {
+	names := sortedCommandNames(commands)
+	var list, cases bytes.Buffer
+	for _, name := range names {
+		command := commands[name]
+		fmt.Fprintf(&list, "  '%s:%s'\n", zshEscape(strings.Replace(name, ":", `\:`, -1)), zshEscape(command.Description))
+		specs := []string{}
+		for _, flag := range command.allFlags() {
+			if flag.Hidden {
+				continue
+			}
+			if spec := zshFlagSpec(flag); spec != "" {
+				specs = append(specs, spec)
+			}
+		}
+		for i, arg := range command.Args {
+			specs = append(specs, zshArgSpec(i+1, arg))
+		}
+		if len(specs) > 0 {
+			if command.VariableArgs || len(command.Args) == 0 {
+				specs = append(specs, "'*:file:_files'")
+			}
+			fmt.Fprintf(&cases, "    %s)\n      _arguments -S \\\n        %s\n      ;;\n", name, strings.Join(specs, " \\\n        "))
+		}
+	}
+	return fmt.Sprintf(`#compdef heroku
+# heroku zsh completion
+# generated by "heroku autocomplete zsh", regenerate it after installing plugins
+
+_heroku_commands=(
+%s)
+
+_heroku_values() {
+  local -a values
+  values=(${(f)"$(heroku autocomplete:values -- "${(@)words[1,CURRENT]}" 2>/dev/null)"})
+  compadd -a values
+}
+
+_heroku() {
+  if (( CURRENT == 2 )); then
+    _describe -t commands 'heroku command' _heroku_commands
+    return
+  fi
+  local cmd="$words[2]"
+  shift words
+  (( CURRENT-- ))
+  case "$cmd" in
+%s    *)
+      _files
+      ;;
+  esac
+}
+
+compdef _heroku heroku
+`, list.String(), cases.String())
+}
+
+// zshFlagSpec is the _arguments spec of a flag, or "" for a flag without a name or char
+func zshFlagSpec(flag Flag) string {
+	names := completionFlagNames(flag)
+	if len(names) == 0 {
+		return ""
+	}
+	spec := "'" + names[0]
+	if len(names) > 1 {
+		spec = "'(" + strings.Join(names, " ") + ")'{" + strings.Join(names, ",") + "}'"
+	}
+	description := strings.NewReplacer("[", `\[`, "]", `\]`).Replace(zshEscape(flag.Description))
+	spec += "[" + description + "]"
+	if flag.takesValue() {
+		action := ""
+		switch {
+		case flag.Type == FlagEnum:
+			action = "(" + strings.Join(flag.Options, " ") + ")"
+		case flag.Completion != "":
+			action = "_heroku_values"
+		}
+		spec += ":" + strings.ToLower(flag.Name) + ":" + action
+	}
+	return spec + "'"
+}
+
+func zshArgSpec(position int, arg Arg) string {
+	spec := fmt.Sprintf("'%d:", position)
+	if arg.Optional {
+		spec += ":"
+	}
+	action := "_files"
+	if arg.Completion != "" {
+		action = "_heroku_values"
+	}
+	return spec + strings.ToLower(arg.Name) + ":" + action + "'"
+}
+
+// zshEscape escapes s for use in a single-quoted zsh string
+func zshEscape(s string) string {
+	return strings.Replace(s, "'", `'\''`, -1)
+}
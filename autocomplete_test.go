@@ -0,0 +1,177 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("autocomplete", func() {
+	Context("bash", func() {
+		BeforeEach(func() {
+			cli.Start("heroku", "autocomplete", "bash")
+		})
+
+		It("completes commands and aliases", func() {
+			Expect(stdout()).To(ContainSubstring("auth:login"))
+			Expect(stdout()).To(ContainSubstring(" whoami"))
+		})
+		It("completes flags", func() {
+			Expect(stdout()).To(ContainSubstring(`commands:markdown) words="--dir -d --dry-run" ;;`))
+		})
+		It("registers the completion", func() {
+			Expect(stdout()).To(HaveSuffix("complete -o default -F _heroku heroku\n"))
+		})
+	})
+
+	Context("zsh", func() {
+		BeforeEach(func() {
+			cli.Start("heroku", "autocomplete", "zsh")
+		})
+
+		It("describes commands", func() {
+			Expect(stdout()).To(HavePrefix("#compdef heroku\n"))
+			Expect(stdout()).To(ContainSubstring(`'auth\:login:login with your Heroku credentials.'`))
+		})
+		It("completes flags", func() {
+			Expect(stdout()).To(ContainSubstring(`'--sso[login for enterprise users under SSO]'`))
+		})
+	})
+})
+
+var _ = Describe("autocomplete:values", func() {
+	var topicBackup cli.Topics
+	BeforeEach(func() {
+		topicBackup = cli.CLITopics
+		cli.Completers["test-color"] = &cli.Completer{
+			Values: func(*cli.Context) ([]string, error) { return []string{"red", "blue"}, nil },
+		}
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name: "paint",
+			Commands: cli.Commands{
+				{
+					Flags: cli.Flags{{Name: "color", Char: "c", HasValue: true, Completion: "test-color"}, {Name: "wet"}},
+					Args:  []cli.Arg{{Name: "brush"}, {Name: "shade", Completion: "test-color"}},
+					Run:   func(*cli.Context) {},
+				},
+			},
+		})
+	})
+	AfterEach(func() {
+		cli.CLITopics = topicBackup
+		delete(cli.Completers, "test-color")
+	})
+
+	It("completes a flag value", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "paint", "--wet", "-c", "")
+		Expect(stdout()).To(Equal("blue\nred\n"))
+	})
+	It("completes an argument", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "paint", "--color", "red", "big", "")
+		Expect(stdout()).To(Equal("blue\nred\n"))
+	})
+	It("does not complete an argument without a completer", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "paint", "b")
+		Expect(stdout()).To(Equal(""))
+	})
+	It("calls autocomplete:values from the bash script", func() {
+		cli.Start("heroku", "autocomplete", "bash")
+		Expect(stdout()).To(ContainSubstring(`paint) words="$(heroku autocomplete:values -- "${args[@]:1}" 2>/dev/null)" ;;`))
+	})
+	It("calls _heroku_values from the zsh script", func() {
+		cli.Start("heroku", "autocomplete", "zsh")
+		Expect(stdout()).To(ContainSubstring(`'(--color -c)'{--color,-c}'[]:color:_heroku_values'`))
+		Expect(stdout()).To(ContainSubstring(`'2:shade:_heroku_values'`))
+	})
+	It("skips flags without a name in the zsh script", func() {
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name:     "brush",
+			Commands: cli.Commands{{Flags: cli.Flags{{Description: "nameless"}, {Char: "s", Description: "soft"}}, Run: func(*cli.Context) {}}},
+		})
+		cli.Start("heroku", "autocomplete", "zsh")
+		Expect(stdout()).NotTo(ContainSubstring("nameless"))
+		Expect(stdout()).To(ContainSubstring(`'-s[soft]'`))
+	})
+})
+
+var _ = Describe("completion cache", func() {
+	var topicBackup cli.Topics
+	var cacheHome string
+	var calls int
+	var values []string
+	cachePath := func() string {
+		paths, _ := filepath.Glob(filepath.Join(cli.CacheHome, "completions", "*", "test-size"))
+		if len(paths) == 0 {
+			return ""
+		}
+		return paths[0]
+	}
+	BeforeEach(func() {
+		calls = 0
+		values = []string{"small", "large"}
+		topicBackup = cli.CLITopics
+		cacheHome = cli.CacheHome
+		cli.CacheHome = filepath.Join("tmp", "cache")
+		os.Setenv("HEROKU_API_KEY", "completion-token")
+		cli.Completers["test-size"] = &cli.Completer{
+			CacheTTL: time.Hour,
+			Values: func(*cli.Context) ([]string, error) {
+				calls++
+				return values, nil
+			},
+		}
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name:     "cup",
+			Commands: cli.Commands{{Args: []cli.Arg{{Name: "size", Completion: "test-size"}}, Run: func(*cli.Context) {}}},
+		})
+	})
+	AfterEach(func() {
+		cli.CLITopics = topicBackup
+		os.RemoveAll(cli.CacheHome)
+		cli.CacheHome = cacheHome
+		os.Unsetenv("HEROKU_API_KEY")
+		delete(cli.Completers, "test-size")
+	})
+
+	It("caches values for the account", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		Expect(calls).To(Equal(1))
+		Expect(stdout()).To(Equal("large\nsmall\nlarge\nsmall\n"))
+		Expect(filepath.Base(filepath.Dir(cachePath()))).NotTo(ContainSubstring("completion-token"))
+	})
+	It("refreshes values older than the TTL", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		old := time.Now().Add(-2 * time.Hour)
+		must(os.Chtimes(cachePath(), old, old))
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		Expect(calls).To(Equal(2))
+	})
+	It("does not cache empty values", func() {
+		values = nil
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		Expect(cachePath()).To(Equal(""))
+	})
+	It("ignores an empty cache file", func() {
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		must(ioutil.WriteFile(cachePath(), []byte{}, 0644))
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		Expect(calls).To(Equal(2))
+		Expect(stdout()).To(Equal("large\nsmall\nlarge\nsmall\n"))
+	})
+	It("does not cache values when not logged in", func() {
+		os.Unsetenv("HEROKU_API_KEY")
+		homeDir := cli.HomeDir
+		cli.HomeDir = filepath.Join("tmp", "home")
+		defer func() { cli.HomeDir = homeDir }()
+		cli.Start("heroku", "autocomplete:values", "--", "cup", "")
+		Expect(calls).To(Equal(1))
+		Expect(cachePath()).To(Equal(""))
+	})
+})
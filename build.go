@@ -48,6 +48,14 @@ func init() {
 					},
 					Run: buildBsdiff,
 				},
+				{
+					Command:     "manpages",
+					Description: "generates man pages for every topic and command",
+					Flags: []Flag{
+						{Name: "dir", Char: "d", Required: true, HasValue: true},
+					},
+					Run: buildManpages,
+				},
 			},
 		},
 	}...)
@@ -64,7 +72,8 @@ func buildPlugins(ctx *Context) {
 	}
 	must(CorePlugins.installPackages(plugins...))
 	for _, plugin := range plugins {
-		plugin, err := CorePlugins.ParsePlugin(strings.Split(plugin, "@")[0])
+		name, _ := splitPluginVersion(plugin)
+		plugin, err := CorePlugins.ParsePlugin(name)
 		must(err)
 		CorePlugins.addToCache(plugin)
 	}
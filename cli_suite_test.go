@@ -34,6 +34,7 @@ var _ = BeforeSuite(func() {
 	cli.AppDir, _ = filepath.Abs(filepath.Join("tmp", "dev", "heroku"))
 	cli.CorePlugins.Path = filepath.Join(cli.AppDir, "lib")
 	cli.ExitFn = func(int) {}
+	cli.Interactive = func() bool { return false }
 	os.MkdirAll(filepath.Dir(errLogPath), 0755)
 	cli.ErrLogPath = errLogPath
 	cli.ErrorArrow = "!"
@@ -24,10 +24,8 @@ func init() {
 					Run: func(ctx *Context) {
 						commands := AllCommands().Sort()
 						if ctx.Flags["json"] == true {
-							commands.loadUsages()
-							commands.loadFullHelp()
-							doc := map[string]interface{}{"topics": AllTopics(), "commands": commands}
-							s, _ := json.Marshal(doc)
+							s, err := json.Marshal(buildCommandManifest(AllTopics(), commands))
+							must(err)
 							Println(string(s))
 							return
 						}
@@ -40,9 +38,23 @@ func init() {
 							} else {
 								Printf("%s:%s\n", command.Topic, command.Command)
 							}
+							for _, alias := range command.Aliases {
+								Println(alias)
+							}
+						}
+						for _, topic := range externalTopics() {
+							Println(topic.Name)
 						}
 					},
 				},
+				{
+					Topic:            "commands",
+					Command:          "markdown",
+					Description:      "write a markdown reference of all commands",
+					Flags:            []Flag{{Name: "dir", Char: "d", Required: true, HasValue: true}},
+					DisableAnalytics: true,
+					Run:              commandsMarkdown,
+				},
 			},
 		},
 	}...)
@@ -51,9 +63,14 @@ func init() {
 // Command represents a CLI command.
 // For example, in the command `heroku apps:create` the command would be `create`.
 // They must have a Topic name that links to a real topic's name.
+// Dangerous commands only run after --confirm or a typed confirmation of the app name,
+// or of the org, argument or flag named by ConfirmWith.
+// FlagsBeforeArgs stops parsing flags at the first argument so the rest are passed on as they are.
+// Plugin commands set SupportsDryRun when they read dryRun from their context and send no changes during a dry run.
 type Command struct {
 	Topic            string             `json:"topic"`
 	Command          string             `json:"command,omitempty"`
+	Aliases          []string           `json:"aliases,omitempty"`
 	Plugin           string             `json:"plugin"`
 	Usage            string             `json:"usage"`
 	Description      string             `json:"description"`
@@ -67,7 +84,12 @@ type Command struct {
 	WantsOrg         bool               `json:"wantsOrg"`
 	NeedsAuth        bool               `json:"needsAuth"`
 	VariableArgs     bool               `json:"variableArgs"`
+	FlagsBeforeArgs  bool               `json:"flagsBeforeArgs,omitempty"`
+	SupportsDryRun   bool               `json:"supportsDryRun,omitempty"`
 	DisableAnalytics bool               `json:"disableAnalytics"`
+	Deprecated       *Deprecation       `json:"deprecated,omitempty"`
+	Dangerous        bool               `json:"dangerous,omitempty"`
+	ConfirmWith      string             `json:"confirmWith,omitempty"`
 	Args             []Arg              `json:"args"`
 	Flags            Flags              `json:"flags"`
 	Run              func(ctx *Context) `json:"-"`
@@ -88,15 +110,26 @@ func CommandUsage(c *Command) string {
 	return c.String() + argsString(c.Args)
 }
 
-func (c *Command) buildFlagHelp() string {
-	flags := c.Flags
+// allFlags returns the command's flags including --app, --remote, --org, --confirm and --dry-run
+func (c *Command) allFlags() Flags {
+	flags := append(Flags{}, c.Flags...)
 	if c.NeedsApp || c.WantsApp {
-		flags = append(flags, *AppFlag, *RemoteFlag)
+		flags = append(flags, *AppFlag, *RemoteFlag, *AppsFromFlag)
 	}
 	if c.NeedsOrg || c.WantsOrg {
 		flags = append(flags, *OrgFlag)
 	}
-	flags.Sort()
+	if c.needsConfirmFlag() {
+		flags = append(flags, *ConfirmFlag)
+	}
+	if c.needsDryRunFlag() && c.supportsDryRun() {
+		flags = append(flags, *DryRunFlag)
+	}
+	return flags.Sort()
+}
+
+func (c *Command) buildFlagHelp() string {
+	flags := c.allFlags()
 	lines := make([]string, 0, len(flags))
 	longestFlag := 20
 	for _, flag := range flags {
@@ -108,17 +141,27 @@ func (c *Command) buildFlagHelp() string {
 		if flag.Hidden {
 			continue
 		}
-		if flag.Description == "" {
+		description := flag.Description
+		if env := flag.envVar(); env != "" {
+			description = strings.TrimSpace(description + " (env: " + env + ")")
+		}
+		if flag.Deprecated != nil {
+			description = strings.TrimSpace(description + " (deprecated)")
+		}
+		if description == "" {
 			lines = append(lines, flag.String())
 		} else {
-			lines = append(lines, fmt.Sprintf("%-"+strconv.Itoa(longestFlag)+"s # %s", flag.String(), flag.Description))
+			lines = append(lines, fmt.Sprintf("%-"+strconv.Itoa(longestFlag)+"s # %s", flag.String(), description))
 		}
 	}
 	return strings.Join(lines, "\n")
 }
 
 func (c *Command) buildFullHelp() string {
-	sections := make([]string, 0, 3)
+	sections := make([]string, 0, 4)
+	if c.Deprecated != nil {
+		sections = append(sections, "DEPRECATED: "+c.Deprecated.message(c.String()))
+	}
 	if c.Description != "" {
 		sections = append(sections, c.Description)
 	}
@@ -132,68 +175,12 @@ func (c *Command) buildFullHelp() string {
 	return strings.TrimSuffix(strings.Join(sections, "\n\n"), "\n")
 }
 
-func (c *Command) unexpectedFlagErr(flag string) {
-	flagHelp := c.buildFlagHelp()
-	cmd := "heroku " + c.String()
-	if flagHelp == "" {
-		ExitWithMessage(
-			`Error: Unexpected flag %s
-Usage: %s
-This command does not take any flags.
-
-See more information with %s`,
-			red(flag),
-			cyan("heroku "+CommandUsage(c)),
-			cyan(cmd+" --help"),
-		)
-	}
-	ExitWithMessage(
-		`Error: Unexpected flag %s
-Usage: %s
-
-This flag is invalid for this command. Here are the accepted flags:
-%s
-
-See more information with %s`,
-		red(flag),
-		cyan("heroku "+CommandUsage(c)),
-		flagHelp,
-		cyan(cmd+" --help"),
-	)
-}
-
-func (c *Command) appNeededErr() {
-	ExitWithMessage(
-		`Error: No app specified
-Usage: %s
-We don't know which app to run this on.
-Run this command from inside an app folder or specify which app to use with %s
-
-https://devcenter.heroku.com/articles/using-the-cli#app-commands`,
-		cyan("heroku "+CommandUsage(c)+" --app APP"),
-		cyan("--app APP"),
-	)
-}
-
-func (c *Command) unexpectedArgumentsErr(args []string) {
-	cmd := "heroku " + c.String()
-	ExitWithMessage(
-		`Error: Unexpected %s %s
-Usage: %s
-You gave this command too many arguments. Try the command again without these extra arguments.
-
-See more information with %s`,
-		plural("argument", len(args)),
-		red(strings.Join(args, " ")),
-		cyan("heroku "+CommandUsage(c)),
-		cyan(cmd+" --help"),
-	)
-}
-
 // Commands is a slice of Command structs with some helper methods.
 type Commands []*Command
 
 // Find finds a command and topic matching the cmd string
+// cmd can also be one of the command's aliases.
+// Use findCommand to also find commands through the aliases of their topic.
 func (commands Commands) Find(cmd string) *Command {
 	var topic, command string
 	tc := strings.SplitN(cmd, ":", 2)
@@ -206,6 +193,25 @@ func (commands Commands) Find(cmd string) *Command {
 			return c
 		}
 	}
+	for _, c := range commands {
+		if contains(c.Aliases, cmd) {
+			return c
+		}
+	}
+	return nil
+}
+
+// findCommand finds a command in AllCommands by name, alias or the alias of its topic
+func findCommand(cmd string) *Command {
+	commands := AllCommands()
+	if c := commands.Find(cmd); c != nil {
+		return c
+	}
+	tc := strings.SplitN(cmd, ":", 2)
+	if t := AllTopics().ByAlias(tc[0]); t != nil && t.Name != tc[0] {
+		tc[0] = t.Name
+		return commands.Find(strings.Join(tc, ":"))
+	}
 	return nil
 }
 
@@ -258,9 +264,10 @@ func (commands Commands) Sort() Commands {
 // Arg defines an argument for a command.
 // These will be parsed in Go and passed to the Run method in the Context struct.
 type Arg struct {
-	Name     string `json:"name"`
-	Optional bool   `json:"optional"`
-	Hidden   bool   `json:"hidden"`
+	Name       string `json:"name"`
+	Optional   bool   `json:"optional"`
+	Hidden     bool   `json:"hidden"`
+	Completion string `json:"completion,omitempty"`
 }
 
 func (a *Arg) String() string {
@@ -285,10 +292,11 @@ func argsString(args []Arg) string {
 	return buffer.String()
 }
 
-// AllCommands gets all go/core/user commands
+// AllCommands gets all go/core/user commands.
+// Commands of the plugins chosen in plugin_precedence come first.
 func AllCommands() Commands {
 	commands := CLITopics.Commands()
 	commands = append(commands, UserPlugins.Commands()...)
 	commands = append(commands, CorePlugins.Commands()...)
-	return commands
+	return preferCommands(commands)
 }
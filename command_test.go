@@ -2,6 +2,9 @@ package main_test
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
 
 	cli "github.com/heroku/cli"
@@ -28,8 +31,109 @@ var _ = Describe("Command", func() {
 		testcase("with multiple arguments", &cli.Command{Topic: "apps", Command: "info", Args: []cli.Arg{{Name: "foo"}, {Name: "bar"}}}, "apps:info FOO BAR")
 	})
 
+	Describe("Find", func() {
+		commands := cli.Commands{
+			{Topic: "auth", Command: "login", Aliases: []string{"login"}},
+			{Topic: "auth", Command: "2fa:disable", Aliases: []string{"2fa:disable", "twofactor:disable"}},
+		}
+		It("finds a command by name", func() {
+			Expect(commands.Find("auth:login")).To(Equal(commands[0]))
+		})
+		It("finds a command by alias", func() {
+			Expect(commands.Find("login")).To(Equal(commands[0]))
+			Expect(commands.Find("twofactor:disable")).To(Equal(commands[1]))
+		})
+		It("finds nothing for an unknown command", func() {
+			Expect(commands.Find("auth:unknown")).To(BeNil())
+		})
+	})
+
+	Describe("topic aliases", func() {
+		var topicBackup cli.Topics
+		var ran bool
+		BeforeEach(func() {
+			ran = false
+			topicBackup = cli.CLITopics
+			cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+				Name:    "widgets",
+				Aliases: []string{"wd"},
+				Commands: cli.Commands{
+					{Topic: "widgets", Command: "list", Run: func(ctx *cli.Context) { ran = true }},
+				},
+			})
+		})
+		AfterEach(func() { cli.CLITopics = topicBackup })
+
+		It("runs a command through the alias of its topic", func() {
+			cli.Start("heroku", "wd:list")
+			Expect(ran).To(BeTrue())
+		})
+		It("are not used by Find on a set of commands", func() {
+			commands := cli.Commands{{Topic: "widgets", Command: "list"}}
+			Expect(commands.Find("wd:list")).To(BeNil())
+		})
+	})
+
 	It("shows the version", func() {
 		version := fmt.Sprintf("heroku-cli/%s (%s-%s) %s ?\n", cli.Version, runtime.GOOS, runtime.GOARCH, runtime.Version())
 		Expect(stdout()).To(Equal(version))
 	})
 })
+
+var _ = Describe("deprecated commands", func() {
+	command := &cli.Command{
+		Topic:      "apps",
+		Command:    "old",
+		Deprecated: &cli.Deprecation{Replacement: "apps:new", RemovedIn: "6.0.0"},
+		Flags: cli.Flags{
+			{Name: "legacy", EnvVar: "HEROKU_TEST_LEGACY", Deprecated: &cli.Deprecation{Message: "It no longer does anything."}},
+		},
+	}
+
+	It("warns when a deprecated command is used", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:old"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(stderr()).To(Equal(" !    apps:old is deprecated and will be removed in 6.0.0. Use apps:new instead.\n"))
+	})
+
+	It("warns when a deprecated flag is used", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:old", "--legacy"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(stderr()).To(ContainSubstring(" !    --legacy is deprecated.\n !    It no longer does anything.\n"))
+	})
+
+	It("warns when a deprecated flag is set by its environment variable", func() {
+		os.Setenv("HEROKU_TEST_LEGACY", "1")
+		defer os.Unsetenv("HEROKU_TEST_LEGACY")
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps:old"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.Flags["legacy"]).To(Equal(true))
+		Expect(stderr()).To(ContainSubstring(" !    --legacy (set by HEROKU_TEST_LEGACY) is deprecated.\n !    It no longer does anything.\n"))
+	})
+})
+
+var _ = Describe("commands:markdown", func() {
+	dir := filepath.Join("tmp", "reference")
+	BeforeEach(func() {
+		os.RemoveAll(dir)
+		cli.Start("heroku", "commands:markdown", "--dir", dir)
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("links each topic from the index", func() {
+		index, err := ioutil.ReadFile(filepath.Join(dir, "README.md"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(index)).To(ContainSubstring("* [auth](auth.md) - authentication (login/logout)\n"))
+	})
+
+	It("documents the commands of a topic", func() {
+		page, err := ioutil.ReadFile(filepath.Join(dir, "auth.md"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(page)).To(ContainSubstring("* [heroku auth:login](#heroku-authlogin)\n"))
+		Expect(string(page)).To(ContainSubstring("## heroku auth:login\n\nlogin with your Heroku credentials.\n\nPlugin: heroku-cli " + cli.Version + " (built in)\n\nAliases: `heroku login`\n"))
+		Expect(string(page)).To(ContainSubstring("Usage: heroku auth:login\n\nlogin with your Heroku credentials.\n\n --dry-run"))
+		Expect(string(page)).To(ContainSubstring("\n --sso"))
+	})
+})
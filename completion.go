@@ -0,0 +1,246 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Completer lists the possible values of a flag or argument for shell completion.
+// Flags and Args refer to a completer by name with their Completion field.
+// Names that are not in Completers are looked up in the command's plugin.
+type Completer struct {
+	// CacheTTL is how long values are cached in CacheHome. 0 disables caching.
+	CacheTTL time.Duration
+	// PerApp caches the values separately for each app
+	PerApp bool
+	Values func(ctx *Context) ([]string, error)
+}
+
+// Completers are the built in completers
+var Completers = map[string]*Completer{
+	"app": {
+		CacheTTL: 24 * time.Hour,
+		Values: func(ctx *Context) ([]string, error) {
+			return apiNames(ctx, "/apps")
+		},
+	},
+	"org": {
+		CacheTTL: 24 * time.Hour,
+		Values: func(ctx *Context) ([]string, error) {
+			return apiNames(ctx, "/organizations")
+		},
+	},
+	"remote": {
+		Values: func(ctx *Context) ([]string, error) {
+			remotes, err := gitRemotes()
+			if err != nil {
+				return nil, err
+			}
+			return mapKeys(remotes), nil
+		},
+	},
+}
+
+// pluginCompletionTTL is how long values from plugin completers are cached
+var pluginCompletionTTL = time.Hour
+
+func apiNames(ctx *Context, path string) ([]string, error) {
+	if ctx.APIToken == "" {
+		return nil, nil
+	}
+	var resources []struct {
+		Name string `json:"name"`
+	}
+	req := apiRequest().Auth(ctx.APIToken).Get(path)
+	req.Set("Range", "name ..; max=1000")
+	res, err := req.ReceiveSuccess(&resources)
+	if err != nil {
+		return nil, err
+	}
+	if err := getHTTPError(res); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// completionValues returns the possible values for the last word in args.
+// args are the words following the command name on the command line.
+func completionValues(command *Command, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	flags := command.allFlags()
+	lookupFlag := func(arg string) *Flag {
+		for i, flag := range flags {
+			if (flag.Name != "" && arg == "--"+flag.Name) || (flag.Char != "" && arg == "-"+flag.Char) {
+				return &flags[i]
+			}
+		}
+		return nil
+	}
+	ctx := &Context{Command: command}
+	var completing string
+	position := 0
+	for i := 0; i < len(args)-1; i++ {
+		flag := lookupFlag(args[i])
+		switch {
+		case flag != nil && flag.takesValue():
+			i++
+			if i == len(args)-1 {
+				completing = flag.Completion
+			} else if flag.Name == "app" {
+				ctx.App = args[i]
+			}
+		case strings.HasPrefix(args[i], "-"):
+		default:
+			position++
+		}
+	}
+	cur := args[len(args)-1]
+	if completing == "" {
+		if strings.HasPrefix(cur, "-") {
+			return nil
+		}
+		switch {
+		case position < len(command.Args):
+			completing = command.Args[position].Completion
+		case command.VariableArgs && len(command.Args) > 0:
+			completing = command.Args[len(command.Args)-1].Completion
+		}
+	}
+	if completing == "" {
+		return nil
+	}
+	if ctx.App == "" && (command.NeedsApp || command.WantsApp) {
+		ctx.App, _ = app()
+	}
+	ctx.APIToken = apiToken()
+	values, err := complete(ctx, completing)
+	LogIfError(err)
+	return values
+}
+
+func complete(ctx *Context, name string) ([]string, error) {
+	key := name
+	completer := Completers[name]
+	if completer == nil {
+		completer = pluginCompleter(ctx.Command, name)
+		key = ctx.Command.Plugin + "-" + name
+	}
+	if completer == nil {
+		return nil, fmt.Errorf("completer %s not found", name)
+	}
+	if completer.PerApp {
+		key = key + "-" + ctx.App
+	}
+	account := completionAccount(ctx)
+	cache := completer.CacheTTL > 0 && account != ""
+	path := filepath.Join(CacheHome, "completions", account, key)
+	if cache {
+		if values := readCompletionCache(path, completer.CacheTTL); values != nil {
+			return values, nil
+		}
+	}
+	values, err := completer.Values(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+	if cache && len(values) > 0 {
+		LogIfError(os.MkdirAll(filepath.Dir(path), 0755))
+		LogIfError(ioutil.WriteFile(path, []byte(strings.Join(values, "\n")+"\n"), 0644))
+	}
+	return values, nil
+}
+
+// completionAccount is the directory completions are cached in so accounts do not share them:
+// the login of the user, or a hash of HEROKU_API_KEY. It is "" when not logged in.
+func completionAccount(ctx *Context) string {
+	if ctx.APIToken == "" {
+		return ""
+	}
+	if login := netrcLogin(); login != "" {
+		return login
+	}
+	sum := sha256.Sum256([]byte(ctx.APIToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// readCompletionCache reads cached values, or returns nil if they are missing, empty or older than ttl
+func readCompletionCache(path string, ttl time.Duration) []string {
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) >= ttl {
+		return nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	content := strings.TrimSpace(string(b))
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// pluginCompleter finds a completer exported by the command's plugin
+// in its `completions` object, for example:
+//
+//	exports.completions = {addon: {options: (ctx) => Promise.resolve(['heroku-postgresql'])}}
+func pluginCompleter(command *Command, name string) *Completer {
+	if command == nil || command.Plugin == "" {
+		return nil
+	}
+	for _, plugins := range []*Plugins{UserPlugins, CorePlugins} {
+		plugin := plugins.ByName(command.Plugin)
+		if plugin == nil {
+			continue
+		}
+		if plugin.Executable != "" {
+			return nil
+		}
+		p := plugins
+		return &Completer{
+			CacheTTL: pluginCompletionTTL,
+			PerApp:   true,
+			Values: func(ctx *Context) ([]string, error) {
+				return p.completionValues(plugin, name, ctx)
+			},
+		}
+	}
+	return nil
+}
+
+func (p *Plugins) completionValues(plugin *Plugin, name string, ctx *Context) ([]string, error) {
+	ctxJSON, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	script := fmt.Sprintf(`'use strict'
+let plugin = require('%s')
+let completion = (plugin.completions || {})['%s']
+if (!completion) throw new Error('completion %s not found')
+Promise.resolve(completion.options(%s)).then((values) => console.log(JSON.stringify(values || [])))
+`, plugin.Name, name, name, ctxJSON)
+	cmd, done := p.RunScript(script)
+	output, err := cmd.Output()
+	done()
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	err = json.Unmarshal(output, &values)
+	return values, err
+}
@@ -7,8 +7,11 @@ import (
 
 // Config interacts with the config.json
 type Config struct {
-	SkipAnalytics *bool `json:"skip_analytics"`
-	Color         *bool `json:"color"`
+	SkipAnalytics     *bool             `json:"skip_analytics"`
+	Color             *bool             `json:"color"`
+	FlagAbbreviations *bool             `json:"flag_abbreviations,omitempty"`
+	Aliases           map[string]string `json:"aliases,omitempty"`
+	PluginPrecedence  map[string]string `json:"plugin_precedence,omitempty"`
 }
 
 var config *Config
@@ -0,0 +1,76 @@
+package main
+
+import "fmt"
+
+// ConfirmFlag is --confirm for Dangerous commands
+var ConfirmFlag = &Flag{
+	Name:        "confirm",
+	HasValue:    true,
+	Description: "confirm the destructive action without a prompt",
+}
+
+// needsConfirmFlag is true when ConfirmFlag is added to a command's flags.
+// Commands that declare their own --confirm flag keep it.
+func (c *Command) needsConfirmFlag() bool {
+	if !c.Dangerous {
+		return false
+	}
+	for _, flag := range c.Flags {
+		if flag.Name == ConfirmFlag.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// confirmationValue is the name and value that has to be typed to run a Dangerous command
+func confirmationValue(ctx *Context) (string, string) {
+	name := ctx.Command.ConfirmWith
+	switch name {
+	case "", "app":
+		return "app", ctx.App
+	case "org":
+		return "org", ctx.Org
+	}
+	if args, ok := ctx.Args.(map[string]string); ok && args[name] != "" {
+		return name, args[name]
+	}
+	value, _ := ctx.Flags[name].(string)
+	return name, value
+}
+
+// confirm checks that --confirm matches the confirmation value of a Dangerous command,
+// or asks for it to be typed when there is a terminal
+func confirm(ctx *Context) error {
+	cmd := "heroku " + ctx.Command.String()
+	if len(ctx.Apps) > 1 {
+		currentAnalyticsCommand.RecordConfirmation("refused")
+		return &ConfirmationError{cmd + " is destructive and can only be run against one app at a time."}
+	}
+	name, expected := confirmationValue(ctx)
+	if expected == "" {
+		return &ConfirmationError{fmt.Sprintf("%s is destructive but has no %s to confirm.", cmd, name)}
+	}
+	given, _ := ctx.Flags[ConfirmFlag.Name].(string)
+	method := "flag"
+	if given == "" {
+		if !Interactive() {
+			currentAnalyticsCommand.RecordConfirmation("refused")
+			return &ConfirmationError{fmt.Sprintf("%s is destructive. To proceed, run it again with %s", cmd, cyan("--confirm "+expected))}
+		}
+		Warn(fmt.Sprintf("WARNING: %s is a destructive action for %s %s.", cmd, name, red(expected)))
+		var err error
+		given, err = promptLine(fmt.Sprintf("To proceed, type %s or run this command again with %s\n> ", red(expected), cyan("--confirm "+expected)), false)
+		if err != nil {
+			currentAnalyticsCommand.RecordConfirmation("refused")
+			return &ConfirmationError{"Aborted."}
+		}
+		method = "prompt"
+	}
+	if given != expected {
+		currentAnalyticsCommand.RecordConfirmation("refused")
+		return &ConfirmationError{fmt.Sprintf("Confirmation %s did not match %s. Aborted.", red(given), red(expected))}
+	}
+	currentAnalyticsCommand.RecordConfirmation(method)
+	return nil
+}
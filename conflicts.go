@@ -0,0 +1,213 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// builtinPluginName is the plugin name of the commands built into the CLI
+const builtinPluginName = "heroku-cli"
+
+// CommandConflict is a command name that commands in more than one plugin have.
+// Winner is the plugin whose command runs. Preferred is true when plugin_precedence chose it.
+type CommandConflict struct {
+	Command   string
+	Plugins   []string
+	Winner    string
+	Preferred bool
+}
+
+func (c *CommandConflict) String() string {
+	return "heroku " + c.Command + " is a command in " + strings.Join(c.Plugins, " and ") + ". Running the one in " + c.Winner + ".\n" +
+		"Choose which runs with " + cyan("heroku plugins:prefer "+c.Command+" PLUGIN")
+}
+
+// commandPluginName is the name of the plugin a command is in
+func commandPluginName(c *Command) string {
+	if c.Plugin == "" {
+		return builtinPluginName
+	}
+	return c.Plugin
+}
+
+// commandNames are the names Commands.Find finds a command by
+func commandNames(c *Command) []string {
+	names := append([]string{c.String()}, c.Aliases...)
+	if c.Default && c.Command != "" {
+		names = append(names, c.Topic)
+	}
+	return names
+}
+
+// findConflicts finds the command names that commands in more than one plugin have.
+// A plugin installed as both a user and a core plugin is not a conflict; the user plugin runs.
+func findConflicts(commands Commands) []*CommandConflict {
+	byName := map[string]*CommandConflict{}
+	names := []string{}
+	for _, c := range commands {
+		plugin := commandPluginName(c)
+		for _, name := range commandNames(c) {
+			conflict, ok := byName[name]
+			if !ok {
+				byName[name] = &CommandConflict{Command: name, Plugins: []string{plugin}}
+				names = append(names, name)
+			} else if !contains(conflict.Plugins, plugin) {
+				conflict.Plugins = append(conflict.Plugins, plugin)
+			}
+		}
+	}
+	sort.Strings(names)
+	conflicts := []*CommandConflict{}
+	for _, name := range names {
+		conflict := byName[name]
+		if len(conflict.Plugins) < 2 {
+			continue
+		}
+		if winner := commands.Find(name); winner != nil {
+			conflict.Winner = commandPluginName(winner)
+		}
+		conflict.Preferred = config != nil && config.PluginPrecedence[name] == conflict.Winner
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// preferCommands moves the commands of the plugins chosen in plugin_precedence
+// in front of the others so Commands.Find finds them first
+func preferCommands(commands Commands) Commands {
+	if config == nil || len(config.PluginPrecedence) == 0 {
+		return commands
+	}
+	preferred := make(Commands, 0, len(config.PluginPrecedence))
+	rest := make(Commands, 0, len(commands))
+	for _, c := range commands {
+		if isPreferredCommand(c) {
+			preferred = append(preferred, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	return append(preferred, rest...)
+}
+
+func isPreferredCommand(c *Command) bool {
+	for _, name := range commandNames(c) {
+		if plugin, ok := config.PluginPrecedence[name]; ok && plugin == commandPluginName(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictsCache is the command names that are in more than one plugin.
+// It is saved when a plugin is installed or linked and removed when the plugin cache changes,
+// so the commands do not have to be compared every time the CLI runs.
+type conflictsCache struct {
+	Version  string   `json:"version"`
+	Commands []string `json:"commands"`
+}
+
+func conflictsCachePath() string {
+	return filepath.Join(UserPlugins.Path, "conflicts.json")
+}
+
+// saveConflicts finds the conflicts and saves their command names in the conflicts cache
+func saveConflicts() []*CommandConflict {
+	conflicts := findConflicts(AllCommands())
+	cache := conflictsCache{Version: Version, Commands: []string{}}
+	for _, conflict := range conflicts {
+		cache.Commands = append(cache.Commands, conflict.Command)
+	}
+	LogIfError(saveJSON(cache, conflictsCachePath()))
+	return conflicts
+}
+
+// conflictedCommands are the command names in the conflicts cache.
+// The cache is saved again if it is missing or from another version of the CLI.
+func conflictedCommands() []string {
+	var cache conflictsCache
+	if err := readJSON(&cache, conflictsCachePath()); err == nil && cache.Version == Version {
+		return cache.Commands
+	}
+	commands := []string{}
+	for _, conflict := range saveConflicts() {
+		commands = append(commands, conflict.Command)
+	}
+	return commands
+}
+
+// warnCommandConflicts warns about the commands of a plugin that other plugins also have
+func warnCommandConflicts(plugin string) {
+	for _, conflict := range saveConflicts() {
+		if contains(conflict.Plugins, plugin) && !conflict.Preferred {
+			Warn(conflict.String())
+		}
+	}
+}
+
+// warnIfConflicted warns when the command being run is in more than one plugin
+// and plugin_precedence does not say which one should run
+func warnIfConflicted(name string) {
+	if !contains(conflictedCommands(), name) {
+		return
+	}
+	for _, conflict := range findConflicts(AllCommands()) {
+		if conflict.Command == name && !conflict.Preferred {
+			Warn(conflict.String())
+		}
+	}
+}
+
+func pluginsConflicts(ctx *Context) {
+	conflicts := findConflicts(AllCommands())
+	if len(conflicts) == 0 {
+		Println("No commands are in more than one plugin.")
+		return
+	}
+	for _, conflict := range conflicts {
+		Printf("=== %s\n", cyan(conflict.Command))
+		for _, plugin := range conflict.Plugins {
+			switch {
+			case plugin == conflict.Winner && conflict.Preferred:
+				Println(plugin + green(" (runs, preferred)"))
+			case plugin == conflict.Winner:
+				Println(plugin + yellow(" (runs)"))
+			default:
+				Println(plugin)
+			}
+		}
+		Println()
+	}
+}
+
+func pluginsPrefer(ctx *Context) {
+	args := ctx.Args.(map[string]string)
+	name, plugin := args["command"], args["plugin"]
+	if plugin == "" {
+		if _, ok := config.PluginPrecedence[name]; !ok {
+			ExitWithMessage("No plugin is preferred for %s.", yellow(name))
+			return
+		}
+		delete(config.PluginPrecedence, name)
+		must(saveJSON(config, configPath()))
+		Printf("Removed the preferred plugin for %s\n", cyan(name))
+		return
+	}
+	found := false
+	for _, c := range AllCommands() {
+		if commandPluginName(c) == plugin && contains(commandNames(c), name) {
+			found = true
+		}
+	}
+	if !found {
+		ExitWithMessage("%s does not have a %s command.", yellow(plugin), yellow(name))
+		return
+	}
+	if config.PluginPrecedence == nil {
+		config.PluginPrecedence = map[string]string{}
+	}
+	config.PluginPrecedence[name] = plugin
+	must(saveJSON(config, configPath()))
+	Printf("heroku %s now runs the command in %s\n", cyan(name), cyan(plugin))
+}
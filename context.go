@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 )
@@ -13,6 +14,7 @@ type Context struct {
 	Topic         *Topic                 `json:"topic"`
 	Command       *Command               `json:"command"`
 	App           string                 `json:"app"`
+	Apps          []string               `json:"apps,omitempty"`
 	Org           string                 `json:"org,omitempty"`
 	Args          interface{}            `json:"args"`
 	Flags         map[string]interface{} `json:"flags"`
@@ -21,6 +23,7 @@ type Context struct {
 	Debug         bool                   `json:"debug"`
 	DebugHeaders  bool                   `json:"debugHeaders"`
 	Dev           bool                   `json:"dev"`
+	DryRun        bool                   `json:"dryRun"`
 	SupportsColor bool                   `json:"supportsColor"`
 	Version       string                 `json:"version"`
 	APIToken      string                 `json:"apiToken"`
@@ -43,24 +46,48 @@ func BuildContext(command *Command, args []string) (*Context, error) {
 	}
 	ctx := &Context{}
 	ctx.Command = command
+	var apps []string
 	if ctx.Command.VariableArgs {
-		ctx.Args, ctx.Flags, ctx.App, err = parseVarArgs(ctx.Command, args[2:])
+		ctx.Args, ctx.Flags, apps, err = parseVarArgs(ctx.Command, args[2:])
 	} else {
-		ctx.Args, ctx.Flags, ctx.App, err = parseArgs(ctx.Command, args[2:])
+		ctx.Args, ctx.Flags, apps, err = parseArgs(ctx.Command, args[2:])
 	}
 	if err != nil {
 		return nil, err
 	}
-	if ctx.Command.NeedsApp || ctx.Command.WantsApp {
+	if ctx.Command.needsDryRunFlag() {
+		flag := ctx.Flags[DryRunFlag.Name] == true
+		delete(ctx.Flags, DryRunFlag.Name)
+		if (flag || dryRunFromEnv()) && !ctx.Command.supportsDryRun() {
+			return nil, &DryRunUnsupportedError{Command: ctx.Command, FromEnv: !flag}
+		}
+		ctx.DryRun = flag || dryRunFromEnv()
+	}
+	dryRun = ctx.DryRun
+	if target := os.Getenv(multiAppTargetEnv); target != "" {
+		os.Unsetenv(multiAppTargetEnv)
+		if contains(apps, target) {
+			apps = []string{target}
+		}
+	}
+	if len(apps) > 1 {
+		ctx.Apps = apps
+	} else if len(apps) == 1 {
+		ctx.App = apps[0]
+	}
+	if ctx.Command.Deprecated != nil {
+		warnDeprecated(ctx.Command.String(), ctx.Command.Deprecated)
+	}
+	if (ctx.Command.NeedsApp || ctx.Command.WantsApp) && ctx.Apps == nil {
 		if ctx.App == "" {
 			var err error
 			ctx.App, err = app()
 			if err != nil && ctx.Command.NeedsApp {
-				ExitWithMessage(err.Error())
+				return nil, err
 			}
 		}
 		if ctx.App == "" && ctx.Command.NeedsApp {
-			ctx.Command.appNeededErr()
+			return nil, &NoAppError{Command: ctx.Command}
 		}
 	}
 	if ctx.Command.NeedsOrg || ctx.Command.WantsOrg {
@@ -70,7 +97,12 @@ func BuildContext(command *Command, args []string) (*Context, error) {
 			ctx.Org = os.Getenv("HEROKU_ORGANIZATION")
 		}
 		if ctx.Org == "" && ctx.Command.NeedsOrg {
-			ExitWithMessage("No org specified.\nRun this command with --org or by setting HEROKU_ORGANIZATION")
+			return nil, &NoOrgError{Command: ctx.Command}
+		}
+	}
+	if ctx.Command.Dangerous {
+		if err := confirm(ctx); err != nil {
+			return nil, err
 		}
 	}
 	if ctx.Command.NeedsAuth {
@@ -90,89 +122,136 @@ func BuildContext(command *Command, args []string) (*Context, error) {
 	return ctx, nil
 }
 
-func parseVarArgs(command *Command, args []string) (result []string, flags map[string]interface{}, appName string, err error) {
+func parseVarArgs(command *Command, args []string) (result []string, flags map[string]interface{}, apps []string, err error) {
 	result = make([]string, 0, len(args))
 	flags = map[string]interface{}{}
 	parseFlags := true
 	possibleFlags := []*Flag{}
-	populateFlagsFromEnvVars(command.Flags, flags)
 	for _, flag := range command.Flags {
 		f := flag
 		possibleFlags = append(possibleFlags, &f)
 	}
 	if command.NeedsApp || command.WantsApp {
-		possibleFlags = append(possibleFlags, AppFlag, RemoteFlag)
+		possibleFlags = append(possibleFlags, AppFlag, RemoteFlag, AppsFromFlag)
 	}
 	if command.NeedsOrg || command.WantsOrg {
 		possibleFlags = append(possibleFlags, OrgFlag)
 	}
+	if command.needsConfirmFlag() {
+		possibleFlags = append(possibleFlags, ConfirmFlag)
+	}
 	warnAboutDuplicateFlags(possibleFlags)
 	for i := 0; i < len(args); i++ {
 		switch {
 		case parseFlags && (args[i] == "--"):
 			parseFlags = false
 		case parseFlags && (args[i] == "--help" || args[i] == "-h"):
-			return nil, nil, "", errHelp
-		case parseFlags && (args[i] == "--no-color"):
+			return nil, nil, nil, errHelp
+		case parseFlags && args[i] == "--no-color":
 			continue
+		case parseFlags && args[i] == DryRunFlag.flagName() && command.needsDryRunFlag() && !(command.VariableArgs && len(result) > 0):
+			flags[DryRunFlag.Name] = true
 		case parseFlags && strings.HasPrefix(args[i], "-"):
-			flag, val, err := ParseFlag(args[i], possibleFlags)
+			parsed, err := ParseFlags(args[i], possibleFlags)
 			if err != nil && strings.HasSuffix(err.Error(), "needs a value") {
 				i++
 				if len(args) == i {
-					ExitWithMessage(err.Error())
+					return nil, nil, nil, &InvalidFlagError{Err: err}
 				}
-				flag, val, err = ParseFlag(args[i-1]+"="+args[i], possibleFlags)
+				parsed, err = ParseFlags(args[i-1]+"="+args[i], possibleFlags)
 			}
 			switch {
 			case err != nil:
-				ExitWithMessage(err.Error())
-			case flag == nil && command.VariableArgs:
+				return nil, nil, nil, &InvalidFlagError{Err: err}
+			case parsed == nil && command.VariableArgs:
 				result = append(result, args[i])
-			case flag == nil:
-				command.unexpectedFlagErr(args[i])
-			case flag == AppFlag:
-				appName = val
-			case flag == RemoteFlag:
-				appName, err = appFromGitRemote(val)
-				if err != nil {
-					ExitWithMessage(err.Error())
+				continue
+			case parsed == nil:
+				return nil, nil, nil, &UnexpectedFlagError{Command: command, Flag: args[i]}
+			}
+			for _, fv := range parsed {
+				flag, val := fv.Flag, fv.Value
+				if flag.Deprecated != nil {
+					warnDeprecated(flag.flagName(), flag.Deprecated)
+				}
+				switch {
+				case flag == AppFlag:
+					apps = appendUnique(apps, val)
+				case flag == RemoteFlag:
+					app, err := appFromGitRemote(val)
+					if err != nil {
+						return nil, nil, nil, err
+					}
+					apps = appendUnique(apps, app)
+				case flag == AppsFromFlag:
+					fromFile, err := readAppsFile(val)
+					if err != nil {
+						return nil, nil, nil, err
+					}
+					apps = appendUnique(apps, fromFile...)
+				case flag.takesValue():
+					v := fv.Parsed
+					if prev, ok := flags[flag.Name].([]string); ok && flag.Type == FlagStrings {
+						v = append(prev, val)
+					}
+					flags[flag.Name] = v
+				default:
+					flags[flag.Name] = true
 				}
-			case flag.HasValue:
-				flags[flag.Name] = val
-			default:
-				flags[flag.Name] = true
 			}
 		default:
 			result = append(result, args[i])
+			if command.FlagsBeforeArgs {
+				parseFlags = false
+			}
 		}
 	}
+	if err := populateFlagsFromEnvVars(command.Flags, flags); err != nil {
+		return nil, nil, nil, &InvalidFlagError{Err: err}
+	}
 	for _, flag := range command.Flags {
 		if flag.Required && flags[flag.Name] == nil {
-			ExitWithMessage("Required flag: %s", flag.String())
+			f := flag
+			if !f.takesValue() || !canPrompt(apps) {
+				return nil, nil, nil, &MissingFlagError{Flag: &f}
+			}
+			if flags[f.Name], err = promptFlag(&f); err != nil {
+				return nil, nil, nil, &MissingFlagError{Flag: &f}
+			}
 		}
 	}
-	return result, flags, appName, nil
+	return result, flags, apps, nil
 }
 
-func parseArgs(command *Command, args []string) (result map[string]string, flags map[string]interface{}, appName string, err error) {
+func parseArgs(command *Command, args []string) (result map[string]string, flags map[string]interface{}, apps []string, err error) {
 	result = map[string]string{}
-	args, flags, appName, err = parseVarArgs(command, args)
+	args, flags, apps, err = parseVarArgs(command, args)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, err
 	}
 	if len(args) > len(command.Args) {
-		command.unexpectedArgumentsErr(args[len(command.Args):])
+		return nil, nil, nil, &UnexpectedArgsError{Command: command, Args: args[len(command.Args):]}
 	}
 	for i, arg := range args {
 		result[command.Args[i].Name] = arg
 	}
 	for _, arg := range command.Args {
 		if !arg.Optional && result[arg.Name] == "" {
-			ExitWithMessage("Missing argument: %s", strings.ToUpper(arg.Name))
+			if !canPrompt(apps) {
+				return nil, nil, nil, &MissingArgError{Command: command, Arg: arg}
+			}
+			if result[arg.Name], err = promptArg(arg); err != nil {
+				return nil, nil, nil, &MissingArgError{Command: command, Arg: arg}
+			}
 		}
 	}
-	return result, flags, appName, nil
+	return result, flags, apps, nil
+}
+
+// canPrompt is true if missing values can be asked for.
+// A command run for more than one app runs in other processes that cannot be given the answers.
+func canPrompt(apps []string) bool {
+	return Interactive() && len(apps) <= 1
 }
 
 func app() (string, error) {
@@ -183,15 +262,39 @@ func app() (string, error) {
 	return appFromGitRemote(remoteFromGitConfig())
 }
 
-func populateFlagsFromEnvVars(flagDefinitons []Flag, flags map[string]interface{}) {
+// populateFlagsFromEnvVars fills in flags that were not given on the command line
+// from their bound environment variables.
+// Precedence is: explicit flag, then environment variable, then the command's own default.
+// Deprecated flags set this way warn just like they do on the command line.
+func populateFlagsFromEnvVars(flagDefinitons []Flag, flags map[string]interface{}) error {
 	for _, flag := range flagDefinitons {
-		if strings.ToLower(flag.Name) == "user" && os.Getenv("HEROKU_USER") != "" {
-			flags[flag.Name] = os.Getenv("HEROKU_USER")
+		env := flag.envVar()
+		if env == "" || flags[flag.Name] != nil {
+			continue
+		}
+		val := os.Getenv(env)
+		if val == "" {
+			continue
+		}
+		switch {
+		case !flag.takesValue():
+			if val == ONE || strings.ToUpper(val) == "TRUE" {
+				flags[flag.Name] = true
+			}
+		case flag.Type == FlagStrings:
+			flags[flag.Name] = strings.Split(val, ",")
+		default:
+			v, err := flag.ParseValue(val)
+			if err != nil {
+				return fmt.Errorf("Invalid %s: %s", env, err)
+			}
+			flags[flag.Name] = v
 		}
-		if strings.ToLower(flag.Name) == "force" && os.Getenv("HEROKU_FORCE") == "1" {
-			flags[flag.Name] = true
+		if flag.Deprecated != nil && flags[flag.Name] != nil {
+			warnDeprecated(flag.flagName()+" (set by "+env+")", flag.Deprecated)
 		}
 	}
+	return nil
 }
 
 func warnAboutDuplicateFlags(flags []*Flag) {
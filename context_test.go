@@ -0,0 +1,318 @@
+package main_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildContext", func() {
+	command := &cli.Command{
+		Topic:   "apps",
+		Command: "info",
+		Args:    []cli.Arg{{Name: "name"}},
+		Flags:   cli.Flags{{Name: "shell", Char: "s"}, {Name: "space", HasValue: true, Required: true}},
+	}
+
+	It("returns an UnexpectedFlagError", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo", "--space=x", "--bar"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.UnexpectedFlagError{}))
+		Expect(err.(cli.ExitCoder).ExitCode()).To(Equal(2))
+	})
+
+	It("returns a MissingArgError", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "--space=x"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.MissingArgError{}))
+		Expect(err.Error()).To(Equal("Missing argument: NAME"))
+	})
+
+	It("returns an UnexpectedArgsError", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo", "bar", "--space=x"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.UnexpectedArgsError{}))
+	})
+
+	It("returns a MissingFlagError", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.MissingFlagError{}))
+		Expect(err.Error()).To(Equal("Required flag:  --space SPACE"))
+	})
+
+	It("returns an InvalidFlagError", func() {
+		_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo", "--space"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.InvalidFlagError{}))
+	})
+
+	It("returns a NoOrgError", func() {
+		_, err := cli.BuildContext(&cli.Command{Topic: "orgs", NeedsOrg: true}, []string{"heroku", "orgs"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.NoOrgError{}))
+	})
+
+	It("returns a NoAppError", func() {
+		_, err := cli.BuildContext(&cli.Command{Topic: "apps", Command: "info", NeedsApp: true}, []string{"heroku", "apps:info"})
+		Expect(err).To(BeAssignableToTypeOf(&cli.NoAppError{}))
+		Expect(err.(cli.ExitCoder).ExitCode()).To(Equal(2))
+		Expect(err.Error()).To(HavePrefix("Error: No app specified\n"))
+		Expect(err.Error()).To(ContainSubstring("heroku apps:info --app APP"))
+	})
+
+	Context("in a repository with more than one app in its git remotes", func() {
+		var cwd string
+		BeforeEach(func() {
+			cwd, _ = os.Getwd()
+			dir := filepath.Join("tmp", "multiple-remotes")
+			os.MkdirAll(dir, 0755)
+			os.Chdir(dir)
+			exec.Command("git", "init", "-q").Run()
+			exec.Command("git", "remote", "add", "heroku", "https://git.heroku.com/app-one.git").Run()
+			exec.Command("git", "remote", "add", "staging", "https://git.heroku.com/app-two.git").Run()
+		})
+		AfterEach(func() {
+			os.Chdir(cwd)
+			os.RemoveAll(filepath.Join("tmp", "multiple-remotes"))
+		})
+
+		It("returns a MultipleRemotesError", func() {
+			cli.Args = []string{"heroku", "apps:info"}
+			_, err := cli.BuildContext(&cli.Command{Topic: "apps", Command: "info", NeedsApp: true}, cli.Args)
+			Expect(err).To(BeAssignableToTypeOf(&cli.MultipleRemotesError{}))
+			Expect(err.(cli.ExitCoder).ExitCode()).To(Equal(2))
+			Expect(err.(*cli.MultipleRemotesError).Remotes).To(Equal([]string{"heroku", "staging"}))
+			Expect(err.Error()).To(HavePrefix("Error: Multiple apps in git remotes\n"))
+			Expect(err.Error()).To(ContainSubstring("heroku apps:info --remote staging"))
+			Expect(err.Error()).To(ContainSubstring("heroku apps:info --app app-two"))
+		})
+	})
+
+	It("accepts --dry-run on any command", func() {
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo", "--space=x", "--dry-run"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.DryRun).To(BeTrue())
+		Expect(ctx.Flags).NotTo(HaveKey("dry-run"))
+	})
+
+	It("sets DryRun from HEROKU_DRY_RUN", func() {
+		os.Setenv("HEROKU_DRY_RUN", "1")
+		defer os.Unsetenv("HEROKU_DRY_RUN")
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo", "--space=x"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.DryRun).To(BeTrue())
+	})
+
+	Context("with --dry-run", func() {
+		It("passes --dry-run on to a command that declares it", func() {
+			own := &cli.Command{Topic: "apps", Command: "migrate", Flags: cli.Flags{{Name: "dry-run"}}}
+			ctx, err := cli.BuildContext(own, []string{"heroku", "apps:migrate", "--dry-run"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.Flags["dry-run"]).To(Equal(true))
+			Expect(ctx.DryRun).To(BeFalse())
+		})
+
+		It("keeps --dry-run after the arguments of a command with variable arguments", func() {
+			run := &cli.Command{Topic: "run", VariableArgs: true}
+			ctx, err := cli.BuildContext(run, []string{"heroku", "run", "python", "manage.py", "makemigrations", "--dry-run"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.Args).To(Equal([]string{"python", "manage.py", "makemigrations", "--dry-run"}))
+			Expect(ctx.DryRun).To(BeFalse())
+		})
+
+		It("keeps --dry-run after --", func() {
+			run := &cli.Command{Topic: "run", VariableArgs: true}
+			ctx, err := cli.BuildContext(run, []string{"heroku", "run", "--", "--dry-run"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.Args).To(Equal([]string{"--dry-run"}))
+			Expect(ctx.DryRun).To(BeFalse())
+		})
+
+		It("refuses a plugin command", func() {
+			plugin := &cli.Command{Topic: "config", Command: "set", Plugin: "heroku-apps", VariableArgs: true}
+			_, err := cli.BuildContext(plugin, []string{"heroku", "config:set", "--dry-run", "FOO=bar"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.DryRunUnsupportedError{}))
+			Expect(err.(cli.ExitCoder).ExitCode()).To(Equal(2))
+			Expect(stripcolor(err.Error())).To(Equal("heroku config:set is a command in the plugin heroku-apps, which does not support --dry-run."))
+		})
+
+		It("refuses HEROKU_DRY_RUN for a plugin command", func() {
+			os.Setenv("HEROKU_DRY_RUN", "1")
+			defer os.Unsetenv("HEROKU_DRY_RUN")
+			plugin := &cli.Command{Topic: "config", Command: "set", Plugin: "heroku-apps", VariableArgs: true}
+			_, err := cli.BuildContext(plugin, []string{"heroku", "config:set", "FOO=bar"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.DryRunUnsupportedError{}))
+			Expect(stripcolor(err.Error())).To(HaveSuffix("does not support --dry-run.\nUnset HEROKU_DRY_RUN to run it."))
+		})
+
+		It("passes the dry run to a plugin command that supports it", func() {
+			plugin := &cli.Command{Topic: "config", Command: "set", Plugin: "heroku-apps", VariableArgs: true, SupportsDryRun: true}
+			ctx, err := cli.BuildContext(plugin, []string{"heroku", "config:set", "--dry-run", "FOO=bar"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.DryRun).To(BeTrue())
+			Expect(ctx.Args).To(Equal([]string{"FOO=bar"}))
+			os.Setenv("HEROKU_DRY_RUN", "1")
+			defer os.Unsetenv("HEROKU_DRY_RUN")
+			ctx, err = cli.BuildContext(plugin, []string{"heroku", "config:set", "FOO=bar"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.DryRun).To(BeTrue())
+		})
+	})
+
+	Context("when interactive", func() {
+		BeforeEach(func() {
+			cli.Interactive = func() bool { return true }
+		})
+		AfterEach(func() {
+			cli.Interactive = func() bool { return false }
+		})
+
+		It("prompts for missing arguments and flags", func() {
+			cli.Stdin = bufio.NewReader(strings.NewReader("us\n\nmyapp\n"))
+			ctx, err := cli.BuildContext(command, []string{"heroku", "apps:info"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.Args).To(Equal(map[string]string{"name": "myapp"}))
+			Expect(ctx.Flags["space"]).To(Equal("us"))
+			Expect(stderr()).To(Equal("--space: NAME: NAME: "))
+		})
+
+		It("does not prompt when running for more than one app", func() {
+			appCommand := &cli.Command{Topic: "apps", Command: "info", NeedsApp: true, Args: []cli.Arg{{Name: "name"}}}
+			cli.Stdin = bufio.NewReader(strings.NewReader("myapp\n"))
+			_, err := cli.BuildContext(appCommand, []string{"heroku", "apps:info", "-a", "app-a", "-a", "app-b"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.MissingArgError{}))
+			Expect(stderr()).To(Equal(""))
+		})
+
+		It("asks again when a value is not valid", func() {
+			typed := &cli.Command{Topic: "apps", Command: "scale", Flags: cli.Flags{{Name: "size", Type: cli.FlagInt, Required: true}}}
+			cli.Stdin = bufio.NewReader(strings.NewReader("big\n2\n"))
+			ctx, err := cli.BuildContext(typed, []string{"heroku", "apps:scale"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.Flags["size"]).To(Equal(2))
+			Expect(stderr()).To(ContainSubstring("--size must be an integer"))
+		})
+
+		It("fails when input ends", func() {
+			cli.Stdin = bufio.NewReader(strings.NewReader(""))
+			_, err := cli.BuildContext(command, []string{"heroku", "apps:info", "foo"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.MissingFlagError{}))
+		})
+	})
+
+	Context("with a dangerous command", func() {
+		dangerous := &cli.Command{Topic: "apps", Command: "destroy", NeedsApp: true, Dangerous: true}
+
+		It("runs when --confirm matches the app", func() {
+			_, err := cli.BuildContext(dangerous, []string{"heroku", "apps:destroy", "-a", "myapp", "--confirm", "myapp"})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("refuses when --confirm does not match the app", func() {
+			_, err := cli.BuildContext(dangerous, []string{"heroku", "apps:destroy", "-a", "myapp", "--confirm", "other"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.ConfirmationError{}))
+			Expect(err.Error()).To(Equal("Confirmation other did not match myapp. Aborted."))
+		})
+
+		It("refuses without --confirm when not interactive", func() {
+			_, err := cli.BuildContext(dangerous, []string{"heroku", "apps:destroy", "-a", "myapp"})
+			Expect(err).To(BeAssignableToTypeOf(&cli.ConfirmationError{}))
+			Expect(err.Error()).To(Equal("heroku apps:destroy is destructive. To proceed, run it again with --confirm myapp"))
+		})
+
+		It("asks for the app name when interactive", func() {
+			cli.Interactive = func() bool { return true }
+			defer func() { cli.Interactive = func() bool { return false } }()
+			cli.Stdin = bufio.NewReader(strings.NewReader("myapp\n"))
+			_, err := cli.BuildContext(dangerous, []string{"heroku", "apps:destroy", "-a", "myapp"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(stderr()).To(ContainSubstring("To proceed, type myapp"))
+		})
+
+		It("confirms with the value named by ConfirmWith", func() {
+			command := &cli.Command{Topic: "spaces", Command: "destroy", Args: []cli.Arg{{Name: "space"}}, Dangerous: true, ConfirmWith: "space"}
+			_, err := cli.BuildContext(command, []string{"heroku", "spaces:destroy", "myspace", "--confirm", "myspace"})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with multiple apps", func() {
+		appCommand := &cli.Command{Topic: "apps", Command: "info", NeedsApp: true}
+		appsFile := filepath.Join("tmp", "apps.txt")
+		BeforeEach(func() {
+			os.MkdirAll(filepath.Dir(appsFile), 0755)
+			ioutil.WriteFile(appsFile, []byte("app-b\n# staging\n\napp-c\n"), 0644)
+		})
+		AfterEach(func() {
+			os.Remove(appsFile)
+		})
+
+		It("sets the app when --app is given once", func() {
+			ctx, err := cli.BuildContext(appCommand, []string{"heroku", "apps:info", "-a", "app-a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.App).To(Equal("app-a"))
+			Expect(ctx.Apps).To(BeNil())
+		})
+
+		It("collects apps from --app and --apps-from", func() {
+			ctx, err := cli.BuildContext(appCommand, []string{"heroku", "apps:info", "-a", "app-a", "--apps-from", appsFile, "--app=app-b"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.App).To(Equal(""))
+			Expect(ctx.Apps).To(Equal([]string{"app-a", "app-b", "app-c"}))
+		})
+
+		It("uses the target app when running for one of many apps", func() {
+			os.Setenv("HEROKU_MULTI_APP_TARGET", "app-c")
+			defer os.Unsetenv("HEROKU_MULTI_APP_TARGET")
+			ctx, err := cli.BuildContext(appCommand, []string{"heroku", "apps:info", "-a", "app-a", "-a", "app-c"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.App).To(Equal("app-c"))
+			Expect(ctx.Apps).To(BeNil())
+			Expect(os.Getenv("HEROKU_MULTI_APP_TARGET")).To(Equal(""))
+		})
+
+		It("does not let the target app override a different --app", func() {
+			os.Setenv("HEROKU_MULTI_APP_TARGET", "app-c")
+			defer os.Unsetenv("HEROKU_MULTI_APP_TARGET")
+			ctx, err := cli.BuildContext(appCommand, []string{"heroku", "apps:info", "-a", "app-a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ctx.App).To(Equal("app-a"))
+		})
+	})
+})
+
+var _ = Describe("running a command for multiple apps", func() {
+	var topicBackup cli.Topics
+	var binPath string
+	script := filepath.Join("tmp", "multiapp", "heroku")
+	BeforeEach(func() {
+		topicBackup = cli.CLITopics
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name:     "multi",
+			Commands: cli.Commands{{Topic: "multi", NeedsApp: true, Run: func(*cli.Context) {}}},
+		})
+		os.MkdirAll(filepath.Dir(script), 0755)
+		ioutil.WriteFile(script, []byte(`#!/bin/sh
+echo "$HEROKU_MULTI_APP_TARGET $*"
+if [ "$HEROKU_MULTI_APP_TARGET" = app-b ]; then exit 3; fi
+`), 0755)
+		binPath = cli.BinPath
+		cli.BinPath = script
+	})
+	AfterEach(func() {
+		cli.BinPath = binPath
+		cli.CLITopics = topicBackup
+		os.RemoveAll(filepath.Dir(script))
+	})
+
+	It("runs the command once for each app and summarizes the results", func() {
+		cli.Start("heroku", "multi", "-a", "app-a", "-a", "app-b")
+		Expect(stdout()).To(ContainSubstring("=== app-a done\napp-a multi -a app-a -a app-b\n"))
+		Expect(stdout()).To(ContainSubstring("=== app-b failed with exit code 3\napp-b multi -a app-a -a app-b\n"))
+		Expect(stdout()).To(HaveSuffix("\nRan heroku multi on 2 apps: 1 succeeded, 1 failed\n"))
+		Expect(stderr()).To(Equal("Failed: app-b\n"))
+	})
+})
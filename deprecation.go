@@ -0,0 +1,31 @@
+package main
+
+import "strings"
+
+// Deprecation marks a command or flag as deprecated.
+// Using a deprecated command or flag still works but prints a warning.
+type Deprecation struct {
+	Message     string `json:"message,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+	RemovedIn   string `json:"removedIn,omitempty"`
+}
+
+func (d *Deprecation) message(name string) string {
+	msg := name + " is deprecated"
+	if d.RemovedIn != "" {
+		msg += " and will be removed in " + d.RemovedIn
+	}
+	msg += "."
+	if d.Replacement != "" {
+		msg += " Use " + d.Replacement + " instead."
+	}
+	if d.Message != "" {
+		msg += "\n" + strings.TrimSpace(d.Message)
+	}
+	return msg
+}
+
+func warnDeprecated(name string, d *Deprecation) {
+	Warn(d.message(name))
+	currentAnalyticsCommand.RecordDeprecation(name)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DryRunFlag is --dry-run, which shows the API requests that would change anything instead of sending them.
+// It can also be turned on with HEROKU_DRY_RUN=1.
+var DryRunFlag = &Flag{
+	Name:        "dry-run",
+	Description: "show the API requests that would change anything instead of sending them",
+}
+
+// needsDryRunFlag is true when DryRunFlag is added to a command's flags.
+// Commands that declare their own --dry-run flag keep it and do their own dry runs.
+func (c *Command) needsDryRunFlag() bool {
+	for _, flag := range c.Flags {
+		if flag.Name == DryRunFlag.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// supportsDryRun is true for commands built into the CLI, which send their requests through apiClient,
+// and for plugin commands that set SupportsDryRun
+func (c *Command) supportsDryRun() bool {
+	return c.Plugin == "" || c.SupportsDryRun
+}
+
+// dryRunFromEnv is true with HEROKU_DRY_RUN=1
+func dryRunFromEnv() bool {
+	e := os.Getenv("HEROKU_DRY_RUN")
+	return e == ONE || strings.ToUpper(e) == "TRUE"
+}
+
+// dryRun is set by BuildContext when the command runs as a dry run
+var dryRun bool
+
+// redactedHeaders are not shown by dry runs
+var redactedHeaders = []string{"Authorization", "Heroku-Password", "Heroku-Two-Factor-Code", "Cookie"}
+
+// DryRunTransport sends GET, HEAD and OPTIONS requests with Transport
+// and shows every other request with a successful empty response
+type DryRunTransport struct {
+	Transport http.RoundTripper
+}
+
+// RoundTrip sends or shows a request
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return t.Transport.RoundTrip(req)
+	}
+	var b bytes.Buffer
+	b.WriteString(yellow("[dry run]") + " " + req.Method + " " + req.URL.String() + "\n")
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(req.Header[name], ", ")
+		if contains(redactedHeaders, http.CanonicalHeaderKey(name)) {
+			value = "[REDACTED]"
+		}
+		b.WriteString("  " + name + ": " + value + "\n")
+	}
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > 0 {
+			b.WriteString("\n  " + strings.Replace(string(body), "\n", "\n  ", -1) + "\n")
+		}
+	}
+	Err(b.String())
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: 200,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+// apiClient is the client for API requests, which only shows changes during a dry run
+func apiClient() *http.Client {
+	if !dryRun {
+		return apiHTTPClient
+	}
+	return &http.Client{
+		Timeout:   apiHTTPClient.Timeout,
+		Transport: &DryRunTransport{apiHTTPClient.Transport},
+	}
+}
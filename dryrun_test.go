@@ -0,0 +1,60 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader("[]")), Request: req}, nil
+}
+
+var _ = Describe("DryRunTransport", func() {
+	var sent *recordingTransport
+	var transport *cli.DryRunTransport
+	BeforeEach(func() {
+		sent = &recordingTransport{}
+		transport = &cli.DryRunTransport{Transport: sent}
+	})
+
+	It("sends GET requests", func() {
+		req, _ := http.NewRequest("GET", "https://api.heroku.com/apps", nil)
+		res, err := transport.RoundTrip(req)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sent.requests).To(HaveLen(1))
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(string(body)).To(Equal("[]"))
+		Expect(stderr()).To(Equal(""))
+	})
+
+	It("shows requests that change anything instead of sending them", func() {
+		req, _ := http.NewRequest("PATCH", "https://api.heroku.com/apps/myapp", strings.NewReader(`{"maintenance":true}`))
+		req.Header.Set("Authorization", "Bearer secret-token")
+		req.Header.Set("Accept", "application/vnd.heroku+json; version=3")
+		res, err := transport.RoundTrip(req)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sent.requests).To(BeEmpty())
+		Expect(stderr()).To(Equal(`[dry run] PATCH https://api.heroku.com/apps/myapp
+  Accept: application/vnd.heroku+json; version=3
+  Authorization: [REDACTED]
+
+  {"maintenance":true}
+`))
+		Expect(stderr()).NotTo(ContainSubstring("secret-token"))
+		Expect(res.StatusCode).To(Equal(200))
+		Expect(res.Header.Get("Content-Type")).To(Equal("application/json"))
+		body, _ := ioutil.ReadAll(res.Body)
+		Expect(string(body)).To(Equal("{}"))
+	})
+})
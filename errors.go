@@ -0,0 +1,183 @@
+package main
+
+import (
+	"strings"
+)
+
+// ExitCoder is an error that knows which exit code the CLI should exit with.
+// Errors returned from BuildContext implement it so they can be handled by callers
+// that embed the parser instead of exiting the process.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// UnexpectedFlagError is returned when a flag is not accepted by a command
+type UnexpectedFlagError struct {
+	Command *Command
+	Flag    string
+}
+
+func (e *UnexpectedFlagError) Error() string {
+	flagHelp := e.Command.buildFlagHelp()
+	cmd := "heroku " + e.Command.String()
+	if flagHelp == "" {
+		return `Error: Unexpected flag ` + red(e.Flag) + `
+Usage: ` + cyan("heroku "+CommandUsage(e.Command)) + `
+This command does not take any flags.
+
+See more information with ` + cyan(cmd+" --help")
+	}
+	return `Error: Unexpected flag ` + red(e.Flag) + `
+Usage: ` + cyan("heroku "+CommandUsage(e.Command)) + `
+
+This flag is invalid for this command. Here are the accepted flags:
+` + flagHelp + `
+
+See more information with ` + cyan(cmd+" --help")
+}
+
+// ExitCode is 2
+func (e *UnexpectedFlagError) ExitCode() int { return 2 }
+
+// InvalidFlagError is returned when a flag is missing its value or the value is invalid
+type InvalidFlagError struct {
+	Err error
+}
+
+func (e *InvalidFlagError) Error() string {
+	return e.Err.Error()
+}
+
+// ExitCode is 2
+func (e *InvalidFlagError) ExitCode() int { return 2 }
+
+// MissingFlagError is returned when a required flag is not given
+type MissingFlagError struct {
+	Flag *Flag
+}
+
+func (e *MissingFlagError) Error() string {
+	return "Required flag: " + e.Flag.String()
+}
+
+// ExitCode is 2
+func (e *MissingFlagError) ExitCode() int { return 2 }
+
+// UnexpectedArgsError is returned when a command is given more arguments than it takes
+type UnexpectedArgsError struct {
+	Command *Command
+	Args    []string
+}
+
+func (e *UnexpectedArgsError) Error() string {
+	return `Error: Unexpected ` + plural("argument", len(e.Args)) + ` ` + red(strings.Join(e.Args, " ")) + `
+Usage: ` + cyan("heroku "+CommandUsage(e.Command)) + `
+You gave this command too many arguments. Try the command again without these extra arguments.
+
+See more information with ` + cyan("heroku "+e.Command.String()+" --help")
+}
+
+// ExitCode is 2
+func (e *UnexpectedArgsError) ExitCode() int { return 2 }
+
+// MissingArgError is returned when a required argument is not given
+type MissingArgError struct {
+	Command *Command
+	Arg     Arg
+}
+
+func (e *MissingArgError) Error() string {
+	return "Missing argument: " + strings.ToUpper(e.Arg.Name)
+}
+
+// ExitCode is 2
+func (e *MissingArgError) ExitCode() int { return 2 }
+
+// NoAppError is returned when a command needs an app and none could be found
+type NoAppError struct {
+	Command *Command
+}
+
+func (e *NoAppError) Error() string {
+	return `Error: No app specified
+Usage: ` + cyan("heroku "+CommandUsage(e.Command)+" --app APP") + `
+We don't know which app to run this on.
+Run this command from inside an app folder or specify which app to use with ` + cyan("--app APP") + `
+
+https://devcenter.heroku.com/articles/using-the-cli#app-commands`
+}
+
+// ExitCode is 2
+func (e *NoAppError) ExitCode() int { return 2 }
+
+// NoOrgError is returned when a command needs an org and none was given
+type NoOrgError struct {
+	Command *Command
+}
+
+func (e *NoOrgError) Error() string {
+	return "No org specified.\nRun this command with --org or by setting HEROKU_ORGANIZATION"
+}
+
+// ExitCode is 2
+func (e *NoOrgError) ExitCode() int { return 2 }
+
+// MultipleRemotesError is returned when the app can't be determined
+// because the git repository references more than one app
+type MultipleRemotesError struct {
+	Remotes []string
+	message string
+}
+
+func (e *MultipleRemotesError) Error() string {
+	return e.message
+}
+
+// ExitCode is 2
+func (e *MultipleRemotesError) ExitCode() int { return 2 }
+
+// DryRunUnsupportedError is returned for a dry run of a plugin command that does not support it,
+// which would still send its requests
+type DryRunUnsupportedError struct {
+	Command *Command
+	FromEnv bool
+}
+
+func (e *DryRunUnsupportedError) Error() string {
+	msg := "heroku " + e.Command.String() + " is a command in the plugin " + e.Command.Plugin + ", which does not support " + red("--dry-run") + "."
+	if e.FromEnv {
+		msg += "\nUnset " + cyan("HEROKU_DRY_RUN") + " to run it."
+	}
+	return msg
+}
+
+// ExitCode is 2
+func (e *DryRunUnsupportedError) ExitCode() int { return 2 }
+
+// ConfirmationError is returned when a Dangerous command was not confirmed
+type ConfirmationError struct {
+	message string
+}
+
+func (e *ConfirmationError) Error() string {
+	return e.message
+}
+
+// ExitCode is 1
+func (e *ConfirmationError) ExitCode() int { return 1 }
+
+// exitWithError shows an error from parsing a command then exits with its exit code
+func exitWithError(err error) {
+	if err == errHelp {
+		help()
+		return
+	}
+	code := 2
+	if e, ok := err.(ExitCoder); ok {
+		code = e.ExitCode()
+	}
+	currentAnalyticsCommand.Valid = false
+	Error(err.Error())
+	Exit(code)
+}
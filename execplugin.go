@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// executablePluginManifest is the file that makes a directory an executable plugin.
+// It has the plugin's name, version, topics and commands in the same format as node plugins,
+// and the path of its executable relative to the directory.
+// Commands that honour dryRun in their context set "supportsDryRun": true.
+//
+//	{
+//	  "name": "heroku-mytool",
+//	  "version": "1.0.0",
+//	  "executable": "bin/mytool",
+//	  "topics": [{"name": "mytool", "description": "run my tool"}],
+//	  "commands": [{"topic": "mytool", "command": "hello", "args": [{"name": "name"}]}]
+//	}
+const executablePluginManifest = "heroku-plugin.json"
+
+// isExecutablePluginDir is true if path is a directory with an executable plugin manifest
+func isExecutablePluginDir(path string) bool {
+	exists, _ := FileExists(filepath.Join(path, executablePluginManifest))
+	return exists
+}
+
+// executablesPath is where executable plugins are installed or linked
+func (p *Plugins) executablesPath() string {
+	return filepath.Join(p.Path, "executables")
+}
+
+// pluginDir is the directory a plugin is installed in
+func (p *Plugins) pluginDir(plugin *Plugin) string {
+	if plugin.Executable != "" {
+		return p.executablePluginPath(plugin.Name)
+	}
+	return p.pluginPath(plugin.Name)
+}
+
+// executablePluginPath is the directory an executable plugin is installed in
+func (p *Plugins) executablePluginPath(name string) string {
+	return filepath.Join(p.executablesPath(), filepath.FromSlash(name))
+}
+
+// ParseExecutablePlugin reads the manifest of the executable plugin installed or linked as name
+func (p *Plugins) ParseExecutablePlugin(name string) (*Plugin, error) {
+	dir := p.executablePluginPath(name)
+	var plugin Plugin
+	if err := readJSON(&plugin, filepath.Join(dir, executablePluginManifest)); err != nil {
+		return nil, fmt.Errorf("Error parsing plugin: %s\n%s", name, err)
+	}
+	if plugin.Name == "" || plugin.Executable == "" {
+		return nil, fmt.Errorf("Invalid plugin. %s needs a name and an executable.", executablePluginManifest)
+	}
+	if len(plugin.Commands) == 0 {
+		return nil, fmt.Errorf("Invalid plugin. No commands found.")
+	}
+	if !isInDir(dir, plugin.Executable) {
+		return nil, fmt.Errorf("Invalid plugin. The executable %s is not in the plugin directory.", plugin.Executable)
+	}
+	if _, err := os.Stat(filepath.Join(dir, plugin.Executable)); err != nil {
+		return nil, fmt.Errorf("Invalid plugin. %s", err)
+	}
+	plugin.UpdatedAt = time.Now()
+	for _, command := range plugin.Commands {
+		if command == nil {
+			continue
+		}
+		command.Plugin = plugin.Name
+		command.Help = strings.TrimSpace(command.Help)
+	}
+	p.addToCache(&plugin)
+	return &plugin, nil
+}
+
+// isInDir is true if the relative path stays inside dir
+func isInDir(dir, path string) bool {
+	if filepath.IsAbs(path) {
+		return false
+	}
+	rel, err := filepath.Rel(dir, filepath.Join(dir, path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// pluginNameRegexp matches npm package names, which cannot leave the plugins directory
+var pluginNameRegexp = regexp.MustCompile(`^(@[a-z0-9][a-z0-9._-]*/)?[a-z0-9][a-z0-9._-]*$`)
+
+// readExecutablePluginManifest reads the manifest of the executable plugin in dir.
+// The name is used as a path in the plugins directory so it has to be a valid plugin name.
+func readExecutablePluginManifest(dir string) (*Plugin, error) {
+	var manifest Plugin
+	if err := readJSON(&manifest, filepath.Join(dir, executablePluginManifest)); err != nil {
+		return nil, err
+	}
+	if manifest.Name == "" {
+		return nil, fmt.Errorf("Invalid plugin. %s needs a name.", executablePluginManifest)
+	}
+	if !pluginNameRegexp.MatchString(manifest.Name) {
+		return nil, fmt.Errorf("Invalid plugin. %s is not a valid plugin name.", manifest.Name)
+	}
+	return &manifest, nil
+}
+
+// installExecutablePlugin copies the executable plugin in dir into the plugins
+func (p *Plugins) installExecutablePlugin(dir string) (*Plugin, error) {
+	manifest, err := readExecutablePluginManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	p.lockPlugin(manifest.Name)
+	defer p.unlockPlugin(manifest.Name)
+	dest := p.executablePluginPath(manifest.Name)
+	if err := os.RemoveAll(dest); err != nil {
+		return nil, err
+	}
+	if err := copyDir(dir, dest); err != nil {
+		return nil, err
+	}
+	plugin, err := p.ParseExecutablePlugin(manifest.Name)
+	if err != nil {
+		return nil, err
+	}
+	return plugin, p.saveLock()
+}
+
+// linkExecutablePlugin symlinks the executable plugin in dir into the plugins
+func (p *Plugins) linkExecutablePlugin(dir string) (*Plugin, error) {
+	manifest, err := readExecutablePluginManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	dest := p.executablePluginPath(manifest.Name)
+	os.Remove(dest)
+	os.RemoveAll(dest)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Symlink(dir, dest); err != nil {
+		return nil, err
+	}
+	return p.ParseExecutablePlugin(manifest.Name)
+}
+
+// runExecutableFn runs a command of an executable plugin.
+// The executable is run with the command name and the arguments given to the CLI.
+// The Context is written as JSON to the file descriptor in HEROKU_CONTEXT_FD:
+// 3 on unix, or 0 (stdin) on Windows.
+// The CLI exits with the executable's exit code.
+func (p *Plugins) runExecutableFn(plugin *Plugin, command *Command) func(ctx *Context) {
+	return func(ctx *Context) {
+		p.readLockPlugin(plugin.Name)
+		ctx.Dev = p.isPluginSymlinked(plugin.Name)
+		ctxJSON, err := json.Marshal(ctx)
+		must(err)
+
+		path := filepath.Join(p.pluginDir(plugin), plugin.Executable)
+		cmd := exec.Command(path, append([]string{command.String()}, Args[2:]...)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		ctxReader, ctxWriter, err := os.Pipe()
+		must(err)
+		if runtime.GOOS == WINDOWS {
+			cmd.Stdin = ctxReader
+			cmd.Env = append(os.Environ(), "HEROKU_CONTEXT_FD=0")
+		} else {
+			cmd.Stdin = os.Stdin
+			cmd.ExtraFiles = []*os.File{ctxReader}
+			cmd.Env = append(os.Environ(), "HEROKU_CONTEXT_FD=3")
+		}
+		if err := cmd.Start(); err != nil {
+			ctxReader.Close()
+			ctxWriter.Close()
+			exitWithError(fmt.Errorf("Could not run heroku %s from the plugin %s.\n%s", command, plugin.Name, err))
+			return
+		}
+		ctxReader.Close()
+
+		if ctx.Dev {
+			currentAnalyticsCommand = nil
+		} else {
+			currentAnalyticsCommand.Plugin = plugin.Name
+			currentAnalyticsCommand.PluginVersion = plugin.Version
+			currentAnalyticsCommand.Language = "executable"
+		}
+
+		// swallow sigint since the plugin will handle it
+		swallowSigint = true
+
+		go func() {
+			ctxWriter.Write(ctxJSON)
+			ctxWriter.Close()
+		}()
+		Exit(getExitCode(cmd.Wait()))
+	}
+}
+
+// copyDir copies the directory src to dest.
+// Symlinks are copied as links, so linked directories are not followed.
+func copyDir(src, dest string) error {
+	src, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		switch {
+		case fi.IsDir():
+			return os.MkdirAll(target, fi.Mode()|0700)
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
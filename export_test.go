@@ -0,0 +1,35 @@
+package main
+
+// InstallExecutablePlugin is installExecutablePlugin for tests
+func (p *Plugins) InstallExecutablePlugin(dir string) (*Plugin, error) {
+	return p.installExecutablePlugin(dir)
+}
+
+// LinkExecutablePlugin is linkExecutablePlugin for tests
+func (p *Plugins) LinkExecutablePlugin(dir string) (*Plugin, error) {
+	return p.linkExecutablePlugin(dir)
+}
+
+// InstallLockedPlugin is installLockedPlugin for tests
+func (p *Plugins) InstallLockedPlugin(locked LockedPlugin) error {
+	return p.installLockedPlugin(locked)
+}
+
+// SplitPluginVersion is splitPluginVersion for tests
+var SplitPluginVersion = splitPluginVersion
+
+// PluginPath is pluginPath for tests
+func (p *Plugins) PluginPath(name string) string {
+	return p.pluginPath(name)
+}
+
+// Lockfile is lockfile for tests
+func (p *Plugins) Lockfile(name string) string {
+	return p.lockfile(name)
+}
+
+// ManpageSources is manpageSources for tests
+var ManpageSources = manpageSources
+
+// ManpageCommand is manpageCommand for tests
+var ManpageCommand = manpageCommand
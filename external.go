@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// externalCommandPrefix is the prefix of executables on PATH that add topics to the CLI.
+// heroku-foo on PATH is run for `heroku foo` and `heroku foo:bar` when foo is not a topic.
+const externalCommandPrefix = "heroku-"
+
+// findExternalCommand is the path of the executable on PATH for the topic of cmd
+func findExternalCommand(cmd string) string {
+	topic := strings.SplitN(cmd, ":", 2)[0]
+	if topic == "" || strings.HasPrefix(topic, "-") || strings.ContainsAny(topic, `/\`) {
+		return ""
+	}
+	path, err := exec.LookPath(externalCommandPrefix + topic)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// runExternalCommand runs an external command and exits with its exit code.
+// `heroku foo:bar ARGS` runs `heroku-foo bar ARGS`.
+// The app, API URL and CLI path are passed in HEROKU_APP, HEROKU_API_URL and HEROKU_BIN.
+// The API token is not passed; the command can get it with `$HEROKU_BIN auth:token`.
+func runExternalCommand(path string, args []string) {
+	currentAnalyticsCommand.RecordStart()
+	currentAnalyticsCommand.Language = "external"
+	tc := strings.SplitN(args[0], ":", 2)
+	if len(tc) > 1 {
+		args = append([]string{tc[1]}, args[1:]...)
+	} else {
+		args = args[1:]
+	}
+	env := append(os.Environ(), "HEROKU_API_URL="+apiURL(), "HEROKU_BIN="+BinPath)
+	if os.Getenv("HEROKU_APP") == "" {
+		if app, err := app(); err == nil && app != "" {
+			env = append(env, "HEROKU_APP="+app)
+		}
+	}
+	swallowSigint = true
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	Exit(getExitCode(cmd.Run()))
+}
+
+// externalTopics are the topics of the external commands on PATH that are not already CLI topics.
+// The first executable on PATH is used for each topic, as it is when running the command.
+func externalTopics() Topics {
+	topics := Topics{}
+	found := map[string]bool{}
+	cliTopics := AllTopics()
+	commands := AllCommands()
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name := file.Name()
+			if runtime.GOOS == WINDOWS {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			topic := strings.TrimPrefix(name, externalCommandPrefix)
+			if !strings.HasPrefix(name, externalCommandPrefix) || topic == "" || found[topic] || file.IsDir() {
+				continue
+			}
+			if runtime.GOOS != WINDOWS && file.Mode()&0111 == 0 {
+				continue
+			}
+			found[topic] = true
+			if cliTopics.ByName(topic) != nil || cliTopics.ByAlias(topic) != nil || commands.Find(topic) != nil {
+				continue
+			}
+			topics = append(topics, &Topic{Name: topic, Description: "external command " + filepath.Join(dir, file.Name())})
+		}
+	}
+	sort.Sort(topics)
+	return topics
+}
@@ -0,0 +1,58 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("external commands", func() {
+	var path, cwd, out string
+	var exitCode int
+	binDir, _ := filepath.Abs(filepath.Join("tmp", "external", "bin"))
+	appDir := filepath.Join("tmp", "external", "app")
+	BeforeEach(func() {
+		out, _ = filepath.Abs(filepath.Join("tmp", "external", "out"))
+		os.MkdirAll(binDir, 0755)
+		ioutil.WriteFile(filepath.Join(binDir, "heroku-extras"), []byte(`#!/bin/sh
+echo "$@" > `+out+`
+echo "$HEROKU_APP" >> `+out+`
+echo "$HEROKU_API_URL" >> `+out+`
+echo "$HEROKU_BIN" >> `+out+`
+exit 4
+`), 0755)
+		path = os.Getenv("PATH")
+		os.Setenv("PATH", binDir+string(os.PathListSeparator)+path)
+		cwd, _ = os.Getwd()
+		os.MkdirAll(appDir, 0755)
+		os.Chdir(appDir)
+		exec.Command("git", "init", "-q").Run()
+		exec.Command("git", "remote", "add", "heroku", "https://git.heroku.com/app-one.git").Run()
+		cli.ExitFn = func(code int) { exitCode = code }
+	})
+	AfterEach(func() {
+		cli.ExitFn = func(int) {}
+		os.Chdir(cwd)
+		os.Setenv("PATH", path)
+		os.RemoveAll(filepath.Join("tmp", "external"))
+	})
+
+	It("runs the executable for the topic with the command as its first argument", func() {
+		cli.Start("heroku", "extras:bar", "baz", "-x")
+		Expect(exitCode).To(Equal(4))
+		output, _ := ioutil.ReadFile(out)
+		Expect(string(output)).To(Equal("bar baz -x\napp-one\nhttps://api.heroku.com\n" + cli.BinPath + "\n"))
+	})
+
+	It("runs the executable for a topic without a command", func() {
+		cli.Start("heroku", "extras", "baz")
+		output, _ := ioutil.ReadFile(out)
+		Expect(string(output)).To(HavePrefix("baz\napp-one\n"))
+	})
+})
@@ -2,27 +2,59 @@ package main
 
 import (
 	"errors"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Flag defines a flag for a command.
 // These will be parsed in Go and passed to the Run method in the Context struct.
+// When a Required flag is missing and stdin is a terminal, its value is prompted for.
+// Secret flags are prompted for without echoing the input.
 type Flag struct {
-	Name        string `json:"name"`
-	Char        string `json:"char"`
-	Description string `json:"description"`
-	HasValue    bool   `json:"hasValue"`
-	Hidden      bool   `json:"hidden"`
-	Required    bool   `json:"required"`
+	Name        string       `json:"name"`
+	Char        string       `json:"char"`
+	Description string       `json:"description"`
+	HasValue    bool         `json:"hasValue"`
+	Hidden      bool         `json:"hidden"`
+	Required    bool         `json:"required"`
+	Type        string       `json:"type,omitempty"`
+	Options     []string     `json:"options,omitempty"`
+	EnvVar      string       `json:"envVar,omitempty"`
+	Deprecated  *Deprecation `json:"deprecated,omitempty"`
+	Completion  string       `json:"completion,omitempty"`
+	Secret      bool         `json:"secret,omitempty"`
 }
 
+// legacyFlagEnvVars are environment variables bound to flags by name
+// before flags could declare their own EnvVar
+var legacyFlagEnvVars = map[string]string{
+	"user":  "HEROKU_USER",
+	"force": "HEROKU_FORCE",
+}
+
+// Flag types.
+// A flag with a Type always takes a value and is stored in the Context with the matching Go type:
+// FlagInt as int, FlagFloat as float64, FlagDuration as time.Duration,
+// FlagEnum as a string that is one of Options, and FlagStrings as a []string
+// with one element per time the flag was given.
+const (
+	FlagInt      = "int"
+	FlagFloat    = "float"
+	FlagDuration = "duration"
+	FlagEnum     = "enum"
+	FlagStrings  = "strings"
+)
+
 // AppFlag is --app
 var AppFlag = &Flag{
 	Name:        "app",
 	Char:        "a",
 	HasValue:    true,
 	Description: "app to run command against",
+	Completion:  "app",
 }
 
 // RemoteFlag is --remote for --app
@@ -31,6 +63,14 @@ var RemoteFlag = &Flag{
 	Char:        "r",
 	HasValue:    true,
 	Description: "git remote of app to run command against",
+	Completion:  "remote",
+}
+
+// AppsFromFlag is --apps-from for running a command against every app listed in a file
+var AppsFromFlag = &Flag{
+	Name:        "apps-from",
+	HasValue:    true,
+	Description: "file of apps to run command against, one per line",
 }
 
 // OrgFlag is --org
@@ -39,6 +79,7 @@ var OrgFlag = &Flag{
 	Char:        "o",
 	HasValue:    true,
 	Description: "organization to use",
+	Completion:  "org",
 }
 
 func (f *Flag) String() string {
@@ -51,14 +92,76 @@ func (f *Flag) String() string {
 	case f.Name != "":
 		s = s + "--" + f.Name
 	}
-	if f.HasValue {
+	if f.takesValue() {
 		s = s + " " + strings.ToUpper(f.Name)
 	}
 	return s
 }
 
+// envVar is the environment variable used when the flag is not passed
+func (f *Flag) envVar() string {
+	if f.EnvVar != "" {
+		return f.EnvVar
+	}
+	return legacyFlagEnvVars[strings.ToLower(f.Name)]
+}
+
+func (f *Flag) takesValue() bool {
+	return f.HasValue || f.Type != ""
+}
+
+func (f *Flag) flagName() string {
+	if f.Name == "" {
+		return "-" + f.Char
+	}
+	return "--" + f.Name
+}
+
+// ParseValue converts a flag's string input into the value for its Type
+func (f *Flag) ParseValue(input string) (interface{}, error) {
+	switch f.Type {
+	case FlagInt:
+		i, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, errors.New(f.flagName() + " must be an integer")
+		}
+		return i, nil
+	case FlagFloat:
+		n, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, errors.New(f.flagName() + " must be a number")
+		}
+		return n, nil
+	case FlagDuration:
+		d, err := time.ParseDuration(input)
+		if err != nil {
+			return nil, errors.New(f.flagName() + " must be a duration such as 30s, 5m or 1h30m")
+		}
+		return d, nil
+	case FlagEnum:
+		if !contains(f.Options, input) {
+			return nil, errors.New(f.flagName() + " must be one of: " + strings.Join(f.Options, ", "))
+		}
+		return input, nil
+	case FlagStrings:
+		return []string{input}, nil
+	case "":
+		return input, nil
+	}
+	return nil, errors.New(f.flagName() + " has unknown type " + f.Type)
+}
+
 // ParseFlag parses a flag from argument inputs
+// A short flag followed by more characters is treated as having a value (-amyapp).
+// Use ParseFlags to also handle combined short flags.
 func ParseFlag(input string, flags []*Flag) (*Flag, string, error) {
+	flag, value, _, err := parseFlag(input, flags)
+	return flag, value, err
+}
+
+// parseFlag is ParseFlag that also returns the value converted for the flag's Type,
+// or true for flags that do not take a value
+func parseFlag(input string, flags []*Flag) (*Flag, string, interface{}, error) {
 	keyvalue := strings.SplitN(input, "=", 2)
 	key := keyvalue[0]
 	value := ""
@@ -66,23 +169,115 @@ func ParseFlag(input string, flags []*Flag) (*Flag, string, error) {
 		value = keyvalue[1]
 	}
 	if len(key) > 2 && key[1] != '-' {
-		return ParseFlag(key[:2]+"="+key[2:], flags)
+		return parseFlag(key[:2]+"="+key[2:], flags)
 	}
-	for _, flag := range flags {
-		if (flag.Char != "" && key == "-"+flag.Char) || key == "--"+flag.Name {
-			if flag.HasValue {
-				if value == "" {
-					return nil, "", errors.New(flag.String() + " needs a value")
-				}
-				return flag, value, nil
+	flag := findFlag(key, flags)
+	if flag == nil && strings.HasPrefix(key, "--") && flagAbbreviations() {
+		var err error
+		if flag, err = findAbbreviatedFlag(key, flags); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	if flag == nil {
+		return nil, "", nil, nil
+	}
+	if flag.takesValue() {
+		if value == "" {
+			return nil, "", nil, errors.New(flag.String() + " needs a value")
+		}
+		parsed, err := flag.ParseValue(value)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return flag, value, parsed, nil
+	}
+	if value != "" {
+		return nil, "", nil, errors.New(flag.String() + " does not take a value")
+	}
+	return flag, "", true, nil
+}
+
+// FlagValue is a flag parsed from an argument along with its value.
+// Parsed is the value converted for the flag's Type, or true for flags that do not take a value.
+type FlagValue struct {
+	Flag   *Flag
+	Value  string
+	Parsed interface{}
+}
+
+// ParseFlags parses all the flags in an argument.
+// Boolean short flags can be combined (-fv), and the last flag
+// of a combination can take a value (-fva myapp or -fvamyapp).
+// It returns nil if the argument contains a flag that is not in flags.
+func ParseFlags(input string, flags []*Flag) ([]FlagValue, error) {
+	if strings.HasPrefix(input, "--") || len(input) <= 2 {
+		flag, value, parsed, err := parseFlag(input, flags)
+		if flag == nil || err != nil {
+			return nil, err
+		}
+		return []FlagValue{{flag, value, parsed}}, nil
+	}
+	cluster := []rune(input[1:])
+	values := make([]FlagValue, 0, len(cluster))
+	for i, c := range cluster {
+		key := "-" + string(c)
+		rest := string(cluster[i+1:])
+		flag := findFlag(key, flags)
+		if flag == nil {
+			return nil, nil
+		}
+		if flag.takesValue() || strings.HasPrefix(rest, "=") {
+			if rest != "" && !strings.HasPrefix(rest, "=") {
+				rest = "=" + rest
 			}
-			if value != "" {
-				return nil, "", errors.New(flag.String() + " does not take a value")
+			flag, value, parsed, err := parseFlag(key+rest, flags)
+			if err != nil {
+				return nil, err
 			}
-			return flag, "", nil
+			return append(values, FlagValue{flag, value, parsed}), nil
+		}
+		values = append(values, FlagValue{flag, "", true})
+	}
+	return values, nil
+}
+
+func findFlag(key string, flags []*Flag) *Flag {
+	for _, flag := range flags {
+		if (flag.Char != "" && key == "-"+flag.Char) || key == "--"+flag.Name {
+			return flag
+		}
+	}
+	return nil
+}
+
+// findAbbreviatedFlag finds the one long flag starting with key
+func findAbbreviatedFlag(key string, flags []*Flag) (*Flag, error) {
+	var matches []*Flag
+	for _, flag := range flags {
+		if flag.Name != "" && strings.HasPrefix("--"+flag.Name, key) {
+			matches = append(matches, flag)
+		}
+	}
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, flag := range matches {
+			names = append(names, "--"+flag.Name)
 		}
+		return nil, errors.New(key + " is ambiguous. It could be " + strings.Join(names, ", "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return nil, nil
+}
+
+// flagAbbreviations is true when long flags can be abbreviated to an unambiguous prefix (--ap for --app).
+// It is enabled with HEROKU_FLAG_ABBREVIATIONS=1 or "flag_abbreviations": true in config.json
+func flagAbbreviations() bool {
+	if e := os.Getenv("HEROKU_FLAG_ABBREVIATIONS"); e != "" {
+		return e == ONE || strings.ToUpper(e) == "TRUE"
 	}
-	return nil, "", nil
+	return config != nil && config.FlagAbbreviations != nil && *config.FlagAbbreviations
 }
 
 // Flags are a list of flags
@@ -1,6 +1,9 @@
 package main_test
 
 import (
+	"os"
+	"time"
+
 	cli "github.com/heroku/cli"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -35,3 +38,147 @@ var _ = Describe("ParseFlag", func() {
 	test(verboseFlag, "--verbose=foo", "", " -v, --verbose does not take a value")
 	test(verboseFlag, "-v", "", "")
 })
+
+var _ = Describe("Flag.ParseValue", func() {
+	test := func(flag *cli.Flag, input string, expected interface{}, expectedErr string) {
+		It(flag.Type+" "+input, func() {
+			val, err := flag.ParseValue(input)
+			if expectedErr != "" {
+				Expect(err.Error()).To(Equal(expectedErr))
+			} else {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(val).To(Equal(expected))
+			}
+		})
+	}
+	limitFlag := &cli.Flag{Name: "limit", Type: cli.FlagInt}
+	test(limitFlag, "10", 10, "")
+	test(limitFlag, "ten", nil, "--limit must be an integer")
+	ratioFlag := &cli.Flag{Name: "ratio", Type: cli.FlagFloat}
+	test(ratioFlag, "0.5", 0.5, "")
+	test(ratioFlag, "half", nil, "--ratio must be a number")
+	waitFlag := &cli.Flag{Name: "wait", Type: cli.FlagDuration}
+	test(waitFlag, "1m30s", 90*time.Second, "")
+	test(waitFlag, "90", nil, "--wait must be a duration such as 30s, 5m or 1h30m")
+	sizeFlag := &cli.Flag{Name: "size", Type: cli.FlagEnum, Options: []string{"small", "large"}}
+	test(sizeFlag, "large", "large", "")
+	test(sizeFlag, "medium", nil, "--size must be one of: small, large")
+	tagFlag := &cli.Flag{Name: "tag", Type: cli.FlagStrings}
+	test(tagFlag, "web", []string{"web"}, "")
+
+	It("validates typed values in ParseFlag", func() {
+		_, _, err := cli.ParseFlag("--limit=ten", []*cli.Flag{limitFlag})
+		Expect(err.Error()).To(Equal("--limit must be an integer"))
+	})
+})
+
+var _ = Describe("typed flags in a Context", func() {
+	command := &cli.Command{
+		Topic: "apps",
+		Flags: cli.Flags{
+			{Name: "limit", Type: cli.FlagInt},
+			{Name: "tag", Char: "t", Type: cli.FlagStrings},
+		},
+	}
+
+	It("stores typed values", func() {
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps", "--limit", "5", "-t", "web", "--tag=worker"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.Flags["limit"]).To(Equal(5))
+		Expect(ctx.Flags["tag"]).To(Equal([]string{"web", "worker"}))
+	})
+})
+
+var _ = Describe("flags bound to environment variables", func() {
+	command := &cli.Command{
+		Topic: "apps",
+		Flags: cli.Flags{
+			{Name: "limit", Type: cli.FlagInt, EnvVar: "HEROKU_TEST_LIMIT"},
+			{Name: "force"},
+		},
+	}
+	BeforeEach(func() {
+		os.Setenv("HEROKU_TEST_LIMIT", "20")
+		os.Setenv("HEROKU_FORCE", "1")
+	})
+	AfterEach(func() {
+		os.Unsetenv("HEROKU_TEST_LIMIT")
+		os.Unsetenv("HEROKU_FORCE")
+	})
+
+	It("uses the environment variable when the flag is not given", func() {
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.Flags["limit"]).To(Equal(20))
+		Expect(ctx.Flags["force"]).To(Equal(true))
+	})
+
+	It("prefers the explicit flag", func() {
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps", "--limit=5"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.Flags["limit"]).To(Equal(5))
+	})
+})
+
+var _ = Describe("ParseFlags", func() {
+	forceFlag := &cli.Flag{Name: "force", Char: "f"}
+	flags := []*cli.Flag{forceFlag, verboseFlag, cli.AppFlag}
+	test := func(input string, expected []cli.FlagValue, expectedErr string) {
+		It(input, func() {
+			out, err := cli.ParseFlags(input, flags)
+			if expectedErr != "" {
+				Expect(err.Error()).To(Equal(expectedErr))
+			} else {
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(out).To(Equal(expected))
+			}
+		})
+	}
+	test("-f", []cli.FlagValue{{forceFlag, "", true}}, "")
+	test("-fv", []cli.FlagValue{{forceFlag, "", true}, {verboseFlag, "", true}}, "")
+	test("-vf", []cli.FlagValue{{verboseFlag, "", true}, {forceFlag, "", true}}, "")
+	test("-fvamyapp", []cli.FlagValue{{forceFlag, "", true}, {verboseFlag, "", true}, {cli.AppFlag, "myapp", "myapp"}}, "")
+	test("-fva=myapp", []cli.FlagValue{{forceFlag, "", true}, {verboseFlag, "", true}, {cli.AppFlag, "myapp", "myapp"}}, "")
+	test("-amyapp", []cli.FlagValue{{cli.AppFlag, "myapp", "myapp"}}, "")
+	test("-afv", []cli.FlagValue{{cli.AppFlag, "fv", "fv"}}, "")
+	test("-fva", nil, " -a, --app APP needs a value")
+	test("-fv=foo", nil, " -v, --verbose does not take a value")
+	test("-fx", nil, "")
+	test("--force", []cli.FlagValue{{forceFlag, "", true}}, "")
+	test("--app=myapp", []cli.FlagValue{{cli.AppFlag, "myapp", "myapp"}}, "")
+
+	Context("with flag abbreviations", func() {
+		forecastFlag := &cli.Flag{Name: "forecast"}
+		flags := []*cli.Flag{forceFlag, forecastFlag, cli.AppFlag}
+		BeforeEach(func() { os.Setenv("HEROKU_FLAG_ABBREVIATIONS", "1") })
+		AfterEach(func() { os.Unsetenv("HEROKU_FLAG_ABBREVIATIONS") })
+
+		It("matches an unambiguous prefix", func() {
+			out, err := cli.ParseFlags("--ap=myapp", flags)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out).To(Equal([]cli.FlagValue{{cli.AppFlag, "myapp", "myapp"}}))
+		})
+		It("errors on an ambiguous prefix", func() {
+			_, err := cli.ParseFlags("--for", flags)
+			Expect(err.Error()).To(Equal("--for is ambiguous. It could be --force, --forecast"))
+		})
+		It("prefers an exact match", func() {
+			out, err := cli.ParseFlags("--force", flags)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out).To(Equal([]cli.FlagValue{{forceFlag, "", true}}))
+		})
+	})
+
+	It("does not match prefixes unless enabled", func() {
+		out, err := cli.ParseFlags("--ap=myapp", flags)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(BeNil())
+	})
+
+	It("takes the value of a combined flag from the next argument", func() {
+		command := &cli.Command{Topic: "apps", Flags: cli.Flags{{Name: "force", Char: "f"}, {Name: "size", Char: "s", HasValue: true}}}
+		ctx, err := cli.BuildContext(command, []string{"heroku", "apps", "-fs", "large"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ctx.Flags).To(Equal(map[string]interface{}{"force": true, "size": "large"}))
+	})
+})
@@ -149,7 +149,7 @@ func errMultipleHerokuRemotes(remotes []string) error {
 		remote = remotes[1]
 	}
 	app, _ := appFromGitRemote(remote)
-	return fmt.Errorf(`Error: Multiple apps in git remotes
+	return &MultipleRemotesError{Remotes: remotes, message: fmt.Sprintf(`Error: Multiple apps in git remotes
 Usage: %s
    or: %s
 
@@ -166,7 +166,7 @@ https://devcenter.heroku.com/articles/multiple-environments`,
 		cyan("--app"),
 		cyan("--remote"),
 		remoteList(remotes),
-	)
+	)}
 }
 
 func remoteList(remotes []string) string {
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -32,15 +33,31 @@ func help() {
 	cmd := Args[1]
 	switch Args[1] {
 	case "help", "--help", "-h":
+		if len(Args) >= 3 && (Args[2] == "--search" || strings.HasPrefix(Args[2], "--search=")) {
+			terms := append([]string{strings.TrimPrefix(strings.TrimPrefix(Args[2], "--search"), "=")}, Args[3:]...)
+			helpSearch(strings.TrimSpace(strings.Join(terms, " ")))
+			return
+		}
 		if len(Args) >= 3 {
 			cmd = Args[2]
 		} else {
 			cmd = ""
 		}
 	}
-	topic := AllTopics().ByName(strings.SplitN(cmd, ":", 2)[0])
-	command := AllCommands().Find(cmd)
+	if cmd != "" {
+		cmd = expandUserAlias([]string{cmd})[0]
+	}
+	topics := AllTopics()
+	command := findCommand(cmd)
+	topic := topics.ByName(strings.SplitN(cmd, ":", 2)[0])
+	if command != nil {
+		topic = topics.ByName(command.Topic)
+	} else if topic == nil {
+		topic = topics.ByAlias(strings.SplitN(cmd, ":", 2)[0])
+	}
 	switch {
+	case topic == nil && command == nil && cmd != "" && findExternalCommand(cmd) != "":
+		runExternalCommand(findExternalCommand(cmd), []string{cmd, "--help"})
 	case topic == nil:
 		helpShowTopics()
 	case command == nil:
@@ -53,7 +70,8 @@ func help() {
 func helpShowTopics() {
 	Printf("Usage: heroku COMMAND [--app APP] [command-specific-options]\n\n")
 	Printf("Help topics, type \"heroku help TOPIC\" for more details:\n\n")
-	topics := AllTopics().NonHidden().Sort()
+	topics := AllTopics()
+	topics = append(append(topics.NonHidden(), aliasTopics(topics, AllCommands())...), externalTopics()...).Sort()
 	longestTopic := 0
 	for _, topic := range topics {
 		if len(topic.Name) > longestTopic {
@@ -67,15 +85,34 @@ func helpShowTopics() {
 	Exit(0)
 }
 
+// aliasTopics lists the top-level aliases of visible commands, like login for auth:login,
+// so they are still shown with the topics they used to be.
+func aliasTopics(topics Topics, commands Commands) Topics {
+	aliases := Topics{}
+	for _, command := range commands.NonHidden() {
+		for _, alias := range command.Aliases {
+			if strings.Contains(alias, ":") || topics.ByName(alias) != nil || aliases.ByName(alias) != nil {
+				continue
+			}
+			aliases = append(aliases, &Topic{Name: alias, Description: command.Description})
+		}
+	}
+	return aliases
+}
+
 func helpShowTopic(topic *Topic) {
-	Printf("Usage: heroku %s:COMMAND [--app APP] [command-specific-options]\n\n", topic.Name)
+	Printf("Usage: heroku %s:COMMAND [--app APP] [command-specific-options]\n", topic.Name)
+	printAliases(topic.Aliases)
+	Println()
 	printTopicCommandsHelp(topic)
 	Println()
 	Exit(0)
 }
 
 func helpShowCommand(topic *Topic, command *Command) {
-	Printf("Usage: heroku %s\n\n", CommandUsage(command))
+	Printf("Usage: heroku %s\n", CommandUsage(command))
+	printAliases(command.Aliases)
+	Println()
 	Println(command.buildFullHelp())
 	if command.Command == "" {
 		printTopicCommandsHelp(topic)
@@ -84,6 +121,12 @@ func helpShowCommand(topic *Topic, command *Command) {
 	Exit(0)
 }
 
+func printAliases(aliases []string) {
+	if len(aliases) > 0 {
+		Printf("Aliases: heroku %s\n", strings.Join(aliases, ", heroku "))
+	}
+}
+
 func printTopicCommandsHelp(topic *Topic) {
 	topicCommands := Commands{}
 	for _, cur := range AllCommands().NonHidden() {
@@ -100,6 +143,81 @@ func printTopicCommandsHelp(topic *Topic) {
 	}
 }
 
+// helpSearchLimit is how many results help --search shows
+const helpSearchLimit = 10
+
+func helpSearch(term string) {
+	if term == "" {
+		ExitWithMessage("Usage: heroku help --search TERM")
+		return
+	}
+	commands := searchCommands(AllCommands().NonHidden(), term)
+	if len(commands) == 0 {
+		ExitWithMessage("No commands match %s.\nRun %s for a list of available topics.", yellow(term), cyan("heroku help"))
+		return
+	}
+	if len(commands) > helpSearchLimit {
+		commands = commands[:helpSearchLimit]
+	}
+	commands.loadUsages()
+	Printf("Commands matching %s, type \"heroku help COMMAND\" for more details:\n\n", term)
+	for _, command := range commands {
+		Printf(" heroku %-30s # %s\n", command.Usage, command.Description)
+	}
+	Println()
+	Exit(0)
+}
+
+// searchCommands returns the commands matching any word of term, best match first.
+// Matches in the command name count the most, then the description,
+// then flags and then the help text.
+func searchCommands(commands Commands, term string) Commands {
+	words := strings.Fields(strings.ToLower(term))
+	scores := map[*Command]int{}
+	results := Commands{}
+	for _, command := range commands {
+		score := 0
+		for _, word := range words {
+			if strings.Contains(strings.ToLower(command.String()), word) {
+				score += 10
+			}
+			for _, alias := range command.Aliases {
+				if strings.Contains(strings.ToLower(alias), word) {
+					score += 10
+					break
+				}
+			}
+			if strings.Contains(strings.ToLower(command.Description), word) {
+				score += 5
+			}
+			for _, flag := range command.Flags {
+				if strings.Contains(strings.ToLower(flag.Name+" "+flag.Description), word) {
+					score += 3
+					break
+				}
+			}
+			if strings.Contains(strings.ToLower(command.Help), word) {
+				score += 2
+			}
+		}
+		if score > 0 {
+			scores[command] = score
+			results = append(results, command)
+		}
+	}
+	sort.Stable(searchResults{results.Sort(), scores})
+	return results
+}
+
+type searchResults struct {
+	Commands
+	scores map[*Command]int
+}
+
+func (r searchResults) Less(i, j int) bool {
+	return r.scores[r.Commands[i]] > r.scores[r.Commands[j]]
+}
+
 func helpInvalidCommand() {
 	checkIfKnownTopic(Args[1])
 	var closest string
@@ -1,6 +1,10 @@
 package main_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	cli "github.com/heroku/cli"
 
 	. "github.com/onsi/ginkgo"
@@ -31,6 +35,12 @@ var _ = Describe("Help", func() {
 		It("shows the help", func() {
 			Expect(stdout()).To(HavePrefix("Usage: heroku COMMAND [--app APP] [command-specific-options]"))
 		})
+		It("lists topics and top-level command aliases", func() {
+			Expect(stdout()).To(MatchRegexp(`(?m)^  heroku auth +# authentication \(login/logout\)$`))
+			Expect(stdout()).To(MatchRegexp(`(?m)^  heroku login +# login with your Heroku credentials\.$`))
+			Expect(stdout()).To(MatchRegexp(`(?m)^  heroku plugins +# `))
+			Expect(stdout()).NotTo(MatchRegexp(`(?m)^  heroku commands +#`))
+		})
 	})
 
 	Context("heroku hlp", func() {
@@ -100,6 +110,47 @@ var _ = Describe("Help", func() {
 		})
 	})
 
+	Context("heroku help --search", func() {
+		It("lists matching commands best match first", func() {
+			cli.Start("heroku", "help", "--search", "recovery", "codes")
+			Expect(exit).To(Equal(0))
+			Expect(stdout()).To(HavePrefix("Commands matching recovery codes"))
+			Expect(stdout()).To(ContainSubstring(" heroku auth:2fa:generate"))
+			Expect(stdout()).NotTo(ContainSubstring("auth:login"))
+		})
+
+		It("ranks name matches above description matches", func() {
+			cli.Start("heroku", "help", "--search=login")
+			Expect(stdout()).To(MatchRegexp(`(?s)auth:login.*auth:whoami`))
+		})
+
+		It("shows an error when nothing matches", func() {
+			cli.Start("heroku", "help", "--search", "xyzzy")
+			Expect(exit).To(Equal(2))
+			Expect(stderr()).To(HavePrefix(" !    No commands match xyzzy."))
+		})
+	})
+
+	Context("with an external command on PATH", func() {
+		binDir := filepath.Join("tmp", "external-bin")
+		var path string
+		BeforeEach(func() {
+			path = os.Getenv("PATH")
+			os.MkdirAll(binDir, 0755)
+			ioutil.WriteFile(filepath.Join(binDir, "heroku-extras"), []byte("#!/bin/sh\n"), 0755)
+			os.Setenv("PATH", binDir+string(os.PathListSeparator)+path)
+		})
+		AfterEach(func() {
+			os.Setenv("PATH", path)
+			os.RemoveAll(binDir)
+		})
+
+		It("lists it as a topic", func() {
+			cli.Start("heroku", "help")
+			Expect(stdout()).To(MatchRegexp(`heroku extras +# external command tmp/external-bin/heroku-extras`))
+		})
+	})
+
 	Context("help command", func() {
 		BeforeEach(func() {
 			cli.AllCommands().Find("help").Run(&cli.Context{})
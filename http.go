@@ -62,7 +62,7 @@ func (api *APIRequest) Auth(token string) *APIRequest {
 }
 
 func apiRequest() *APIRequest {
-	req := sling.New().Client(apiHTTPClient).Base(apiURL())
+	req := sling.New().Client(apiClient()).Base(apiURL())
 	req.Set("User-Agent", version())
 	req.Set("Accept", "application/vnd.heroku+json; version=3")
 	if os.Getenv("HEROKU_HEADERS") != "" {
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PluginLockVersion is the version of the plugins lockfile format
+const PluginLockVersion = 1
+
+// PluginLock records the exact version and integrity hash of every installed plugin
+// so the same plugins can be installed elsewhere with plugins:import.
+// Symlinked plugins are not recorded.
+type PluginLock struct {
+	Version int           `json:"version"`
+	Plugins LockedPlugins `json:"plugins"`
+}
+
+// LockedPlugin is a plugin in the lockfile.
+// Integrity is the npm integrity of the package, or a sha256 of the directory of an executable plugin.
+// Pin is the version or semver range the plugin was installed with, which updates stay within.
+// Previous is the version that was installed before this one, for plugins:rollback.
+type LockedPlugin struct {
+	Name       string        `json:"name"`
+	Version    string        `json:"version"`
+	Integrity  string        `json:"integrity,omitempty"`
+	Executable bool          `json:"executable,omitempty"`
+	Pin        string        `json:"pin,omitempty"`
+	Previous   *LockedPlugin `json:"previous,omitempty"`
+}
+
+// LockedPlugins are sorted by name
+type LockedPlugins []LockedPlugin
+
+func (plugins LockedPlugins) Len() int {
+	return len(plugins)
+}
+
+func (plugins LockedPlugins) Less(i, j int) bool {
+	return plugins[i].Name < plugins[j].Name
+}
+
+func (plugins LockedPlugins) Swap(i, j int) {
+	plugins[i], plugins[j] = plugins[j], plugins[i]
+}
+
+// ByName returns a locked plugin by its name
+func (plugins LockedPlugins) ByName(name string) *LockedPlugin {
+	for i := range plugins {
+		if plugins[i].Name == name {
+			return &plugins[i]
+		}
+	}
+	return nil
+}
+
+func (p *Plugins) pluginLockPath() string {
+	return filepath.Join(p.Path, "plugins.lock.json")
+}
+
+// readLock reads the saved lockfile
+func (p *Plugins) readLock() *PluginLock {
+	lock := &PluginLock{Version: PluginLockVersion, Plugins: LockedPlugins{}}
+	if exists, _ := FileExists(p.pluginLockPath()); exists {
+		WarnIfError(readJSON(lock, p.pluginLockPath()))
+	}
+	return lock
+}
+
+// Lock is the lockfile of the installed plugins.
+// Pins and previous versions are kept from the saved lockfile.
+func (p *Plugins) Lock() (*PluginLock, error) {
+	saved := p.readLock().Plugins
+	lock := &PluginLock{Version: PluginLockVersion, Plugins: LockedPlugins{}}
+	for _, plugin := range p.Plugins() {
+		if p.isPluginSymlinked(plugin.Name) {
+			continue
+		}
+		integrity, err := p.integrity(plugin)
+		if err != nil {
+			return nil, err
+		}
+		locked := LockedPlugin{
+			Name:       plugin.Name,
+			Version:    plugin.Version,
+			Integrity:  integrity,
+			Executable: plugin.Executable != "",
+		}
+		if prev := saved.ByName(plugin.Name); prev != nil {
+			locked.Pin = prev.Pin
+			locked.Previous = prev.Previous
+			if prev.Version != plugin.Version {
+				locked.Previous = &LockedPlugin{Name: prev.Name, Version: prev.Version, Integrity: prev.Integrity, Executable: prev.Executable}
+			}
+		}
+		lock.Plugins = append(lock.Plugins, locked)
+	}
+	sort.Sort(lock.Plugins)
+	return lock, nil
+}
+
+// saveLock writes the lockfile of the installed plugins
+func (p *Plugins) saveLock() error {
+	lock, err := p.Lock()
+	if err != nil {
+		return err
+	}
+	return saveJSON(lock, p.pluginLockPath())
+}
+
+// setPin saves the version or range updates of an installed plugin stay within.
+// An empty pin lets the plugin update to the latest version.
+func (p *Plugins) setPin(name, pin string) error {
+	lock, err := p.Lock()
+	if err != nil {
+		return err
+	}
+	if locked := lock.Plugins.ByName(name); locked != nil {
+		locked.Pin = pin
+	}
+	return saveJSON(lock, p.pluginLockPath())
+}
+
+// pin is the version or range an installed plugin is pinned to
+func (p *Plugins) pin(name string) string {
+	if locked := p.readLock().Plugins.ByName(name); locked != nil {
+		return locked.Pin
+	}
+	return ""
+}
+
+// integrity is the integrity hash npm recorded for the installed package,
+// or a hash of the files of an executable plugin
+func (p *Plugins) integrity(plugin *Plugin) (string, error) {
+	if plugin.Executable != "" {
+		return dirIntegrity(p.pluginDir(plugin))
+	}
+	var pjson struct {
+		Integrity string `json:"_integrity"`
+		Shasum    string `json:"_shasum"`
+	}
+	if err := readJSON(&pjson, filepath.Join(p.pluginDir(plugin), "package.json")); err != nil {
+		return "", err
+	}
+	if pjson.Integrity != "" {
+		return pjson.Integrity, nil
+	}
+	if pjson.Shasum == "" {
+		return "", nil
+	}
+	return shasumIntegrity(pjson.Shasum)
+}
+
+// shasumIntegrity is a hex sha1 shasum as an integrity hash
+func shasumIntegrity(shasum string) (string, error) {
+	sum, err := hex.DecodeString(shasum)
+	if err != nil {
+		return "", err
+	}
+	return "sha1-" + base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// dirIntegrity is a sha256 of the paths and contents of the files in dir.
+// Symlinks are hashed by where they point, like copyDir copies them.
+func dirIntegrity(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			io.WriteString(h, filepath.ToSlash(rel)+"\x00-> "+link)
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		io.WriteString(h, filepath.ToSlash(rel)+"\x00")
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// isInstalled is true if the locked version of a plugin is installed and unchanged
+func (p *Plugins) isInstalled(locked LockedPlugin) bool {
+	plugin := p.ByName(locked.Name)
+	if plugin == nil || plugin.Version != locked.Version {
+		return false
+	}
+	integrity, err := p.integrity(plugin)
+	return err == nil && integrity == locked.Integrity
+}
+
+// installLockedPlugin installs the exact version of a plugin in a lockfile.
+// Its integrity is checked against the registry before anything is installed,
+// so a mismatch leaves the installed version of the plugin as it is.
+func (p *Plugins) installLockedPlugin(locked LockedPlugin) error {
+	if locked.Integrity != "" {
+		integrities, err := p.PackageIntegrities(locked.Name, locked.Version)
+		if err != nil {
+			return err
+		}
+		if !contains(integrities, locked.Integrity) {
+			return fmt.Errorf("Integrity check failed for %s@%s\nExpected %s but the registry has %s", locked.Name, locked.Version, locked.Integrity, strings.Join(integrities, " "))
+		}
+	}
+	p.lockPlugin(locked.Name)
+	defer p.unlockPlugin(locked.Name)
+	if err := p.installPackages(locked.Name + "@" + locked.Version); err != nil {
+		return err
+	}
+	_, err := p.ParsePlugin(locked.Name)
+	return err
+}
@@ -0,0 +1,185 @@
+package main
+
+// CommandManifestVersion is the version of the `commands --json` output.
+// It only changes when the output changes in a way that breaks existing readers.
+// The output is described by the JSON Schema in resources/commands.schema.json.
+const CommandManifestVersion = 1
+
+// CommandManifest is the output of `commands --json`.
+// It is kept separate from Command and Topic so those can change without changing the output.
+type CommandManifest struct {
+	Version    int               `json:"version"`
+	CLIVersion string            `json:"cliVersion"`
+	Topics     []ManifestTopic   `json:"topics"`
+	Commands   []ManifestCommand `json:"commands"`
+}
+
+// ManifestTopic is a topic in the command manifest
+type ManifestTopic struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Aliases     []string `json:"aliases"`
+}
+
+// ManifestCommand is a command in the command manifest
+type ManifestCommand struct {
+	ID           string          `json:"id"`
+	Topic        string          `json:"topic"`
+	Command      string          `json:"command"`
+	Aliases      []string        `json:"aliases"`
+	Usage        string          `json:"usage"`
+	Description  string          `json:"description"`
+	Help         string          `json:"help"`
+	Plugin       *ManifestPlugin `json:"plugin"`
+	NeedsApp     bool            `json:"needsApp"`
+	WantsApp     bool            `json:"wantsApp"`
+	NeedsOrg     bool            `json:"needsOrg"`
+	WantsOrg     bool            `json:"wantsOrg"`
+	NeedsAuth    bool            `json:"needsAuth"`
+	VariableArgs bool            `json:"variableArgs"`
+	DryRun       bool            `json:"dryRun"`
+	Dangerous    bool            `json:"dangerous"`
+	ConfirmWith  string          `json:"confirmWith,omitempty"`
+	Deprecated   *Deprecation    `json:"deprecated"`
+	Args         []ManifestArg   `json:"args"`
+	Flags        []ManifestFlag  `json:"flags"`
+}
+
+// ManifestPlugin is the plugin a command comes from.
+// It is null in the manifest for commands built into the CLI.
+type ManifestPlugin struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Core    bool   `json:"core"`
+}
+
+// ManifestArg is a command argument in the command manifest
+type ManifestArg struct {
+	Name       string `json:"name"`
+	Optional   bool   `json:"optional"`
+	Completion string `json:"completion,omitempty"`
+}
+
+// ManifestFlag is a command flag in the command manifest.
+// Type is "boolean" for flags without a value, "string" for flags with
+// an untyped value, or one of the flag types such as "int".
+type ManifestFlag struct {
+	Name        string       `json:"name"`
+	Char        string       `json:"char,omitempty"`
+	Description string       `json:"description"`
+	Type        string       `json:"type"`
+	Options     []string     `json:"options,omitempty"`
+	Required    bool         `json:"required"`
+	EnvVar      string       `json:"envVar,omitempty"`
+	Deprecated  *Deprecation `json:"deprecated"`
+	Completion  string       `json:"completion,omitempty"`
+	Secret      bool         `json:"secret,omitempty"`
+}
+
+func buildCommandManifest(topics Topics, commands Commands) *CommandManifest {
+	manifest := &CommandManifest{
+		Version:    CommandManifestVersion,
+		CLIVersion: Version,
+		Topics:     []ManifestTopic{},
+		Commands:   []ManifestCommand{},
+	}
+	for _, topic := range topics.NonHidden().Sort() {
+		manifest.Topics = append(manifest.Topics, ManifestTopic{
+			Name:        topic.Name,
+			Description: topic.Description,
+			Aliases:     nonNilStrings(topic.Aliases),
+		})
+	}
+	plugins := commandPlugins()
+	commands = Commands(commands.NonHidden()).Sort()
+	commands.loadUsages()
+	commands.loadFullHelp()
+	for _, command := range commands {
+		plugin := plugins[command.Plugin]
+		if plugin == nil && command.Plugin != "" {
+			plugin = &ManifestPlugin{Name: command.Plugin}
+		}
+		manifest.Commands = append(manifest.Commands, ManifestCommand{
+			ID:           command.String(),
+			Topic:        command.Topic,
+			Command:      command.Command,
+			Aliases:      nonNilStrings(command.Aliases),
+			Usage:        command.Usage,
+			Description:  command.Description,
+			Help:         command.FullHelp,
+			Plugin:       plugin,
+			NeedsApp:     command.NeedsApp,
+			WantsApp:     command.WantsApp,
+			NeedsOrg:     command.NeedsOrg,
+			WantsOrg:     command.WantsOrg,
+			NeedsAuth:    command.NeedsAuth,
+			VariableArgs: command.VariableArgs,
+			DryRun:       command.needsDryRunFlag() && command.supportsDryRun(),
+			Dangerous:    command.Dangerous,
+			ConfirmWith:  command.ConfirmWith,
+			Deprecated:   command.Deprecated,
+			Args:         manifestArgs(command.Args),
+			Flags:        manifestFlags(command.allFlags()),
+		})
+	}
+	return manifest
+}
+
+func manifestArgs(args []Arg) []ManifestArg {
+	to := []ManifestArg{}
+	for _, arg := range args {
+		if !arg.Hidden {
+			to = append(to, ManifestArg{Name: arg.Name, Optional: arg.Optional, Completion: arg.Completion})
+		}
+	}
+	return to
+}
+
+func manifestFlags(flags Flags) []ManifestFlag {
+	to := []ManifestFlag{}
+	for _, flag := range flags {
+		if flag.Hidden {
+			continue
+		}
+		t := flag.Type
+		switch {
+		case t != "":
+		case flag.HasValue:
+			t = "string"
+		default:
+			t = "boolean"
+		}
+		to = append(to, ManifestFlag{
+			Name:        flag.Name,
+			Char:        flag.Char,
+			Description: flag.Description,
+			Type:        t,
+			Options:     flag.Options,
+			Required:    flag.Required,
+			EnvVar:      flag.envVar(),
+			Deprecated:  flag.Deprecated,
+			Completion:  flag.Completion,
+			Secret:      flag.Secret,
+		})
+	}
+	return to
+}
+
+// commandPlugins maps the names of the installed core and user plugins to their manifest entry
+func commandPlugins() map[string]*ManifestPlugin {
+	plugins := map[string]*ManifestPlugin{}
+	for _, plugin := range UserPlugins.Plugins() {
+		plugins[plugin.Name] = &ManifestPlugin{Name: plugin.Name, Version: plugin.Version}
+	}
+	for _, plugin := range CorePlugins.Plugins() {
+		plugins[plugin.Name] = &ManifestPlugin{Name: plugin.Name, Version: plugin.Version, Core: true}
+	}
+	return plugins
+}
+
+func nonNilStrings(a []string) []string {
+	if a == nil {
+		return []string{}
+	}
+	return a
+}
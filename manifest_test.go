@@ -0,0 +1,121 @@
+package main_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("commands --json", func() {
+	var manifest map[string]interface{}
+	BeforeEach(func() {
+		cli.Start("heroku", "commands", "--json")
+		must(json.Unmarshal([]byte(stdout()), &manifest))
+	})
+
+	findCommand := func(id string) map[string]interface{} {
+		for _, c := range manifest["commands"].([]interface{}) {
+			if c.(map[string]interface{})["id"] == id {
+				return c.(map[string]interface{})
+			}
+		}
+		return nil
+	}
+
+	It("is versioned", func() {
+		Expect(manifest["version"]).To(BeEquivalentTo(cli.CommandManifestVersion))
+		Expect(manifest["cliVersion"]).To(Equal(cli.Version))
+	})
+
+	It("describes commands", func() {
+		login := findCommand("auth:login")
+		Expect(login).NotTo(BeNil())
+		Expect(login["aliases"]).To(Equal([]interface{}{"login"}))
+		Expect(login["plugin"]).To(BeNil())
+		Expect(login["flags"]).To(ContainElement(map[string]interface{}{
+			"name":        "sso",
+			"description": "login for enterprise users under SSO",
+			"type":        "boolean",
+			"required":    false,
+			"deprecated":  nil,
+		}))
+	})
+
+	It("describes dangerous commands", func() {
+		Expect(findCommand("auth:login")["dryRun"]).To(Equal(true))
+		Expect(findCommand("auth:login")["dangerous"]).To(Equal(false))
+		Expect(findCommand("auth:login")).NotTo(HaveKey("confirmWith"))
+		topicBackup := cli.CLITopics
+		defer func() { cli.CLITopics = topicBackup }()
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name:     "spaces",
+			Commands: cli.Commands{{Command: "destroy", Dangerous: true, ConfirmWith: "space", Args: []cli.Arg{{Name: "space"}}}},
+		})
+		cli.Stdout = new(bytes.Buffer)
+		cli.Start("heroku", "commands", "--json")
+		manifest = nil
+		must(json.Unmarshal([]byte(stdout()), &manifest))
+		destroy := findCommand("spaces:destroy")
+		Expect(destroy["dangerous"]).To(Equal(true))
+		Expect(destroy["confirmWith"]).To(Equal("space"))
+		Expect(destroy["flags"]).To(ContainElement(HaveKeyWithValue("name", "confirm")))
+		b, err := ioutil.ReadFile(filepath.Join("resources", "commands.schema.json"))
+		Expect(err).ShouldNot(HaveOccurred())
+		var schema map[string]interface{}
+		must(json.Unmarshal(b, &schema))
+		Expect(schemaErrors(schema, schema, manifest, "")).To(BeEmpty())
+	})
+
+	It("leaves out hidden commands", func() {
+		Expect(findCommand("autocomplete:values")).To(BeNil())
+	})
+
+	It("matches the published schema", func() {
+		b, err := ioutil.ReadFile(filepath.Join("resources", "commands.schema.json"))
+		Expect(err).ShouldNot(HaveOccurred())
+		var schema map[string]interface{}
+		must(json.Unmarshal(b, &schema))
+		Expect(schemaErrors(schema, schema, manifest, "")).To(BeEmpty())
+	})
+})
+
+// schemaErrors checks that objects only have the properties the schema
+// declares and have all the required ones
+func schemaErrors(root, schema map[string]interface{}, value interface{}, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		schema = root["definitions"].(map[string]interface{})[name].(map[string]interface{})
+	}
+	errs := []string{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, item := range v {
+			property, ok := properties[key].(map[string]interface{})
+			if !ok {
+				errs = append(errs, path+"."+key+" is not in the schema")
+				continue
+			}
+			errs = append(errs, schemaErrors(root, property, item, path+"."+key)...)
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, key := range required {
+			if _, ok := v[key.(string)]; !ok {
+				errs = append(errs, path+"."+key.(string)+" is required")
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, item := range v {
+			errs = append(errs, schemaErrors(root, items, item, path+"[]")...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func buildManpages(ctx *Context) {
+	dir := ctx.Flags["dir"].(string)
+	must(os.MkdirAll(dir, 0755))
+	topics, commands := manpageSources()
+	must(writeManpage(dir, "heroku", manpageIndex(topics)))
+	for _, topic := range topics {
+		topicCommands := Commands{}
+		var root *Command
+		for _, command := range commands.NonHidden() {
+			switch {
+			case command.Topic != topic.Name:
+			case command.Command == "":
+				root = command
+			default:
+				topicCommands = append(topicCommands, command)
+			}
+		}
+		must(writeManpage(dir, manpageName(topic.Name), manpageTopic(topic, root, topicCommands)))
+		for _, command := range topicCommands {
+			must(writeManpage(dir, manpageName(command.String()), manpageCommand(command)))
+		}
+		for _, command := range append(Commands{root}, topicCommands...) {
+			if command == nil {
+				continue
+			}
+			for _, alias := range command.Aliases {
+				link := ".so man1/" + manpageName(command.String()) + ".1\n"
+				must(writeManpage(dir, manpageName(alias), link))
+			}
+		}
+	}
+}
+
+// manpageSources returns the topics and commands to write man pages for.
+// The pages are shipped in packages so they only describe the Go commands and core plugins,
+// never the user plugins installed on the machine building them.
+func manpageSources() (Topics, Commands) {
+	topics := CLITopics.Concat(CorePlugins.Topics()).NonHidden().Sort()
+	commands := append(CLITopics.Commands(), CorePlugins.Commands()...).Sort()
+	return topics, commands
+}
+
+func writeManpage(dir, name, page string) error {
+	return ioutil.WriteFile(filepath.Join(dir, name+".1"), []byte(page), 0644)
+}
+
+// manpageName is the name of the man page for a topic or command
+// apps:info becomes heroku-apps-info
+func manpageName(cmd string) string {
+	return "heroku-" + strings.Replace(cmd, ":", "-", -1)
+}
+
+func manpageHeader(b *bytes.Buffer, name, description string) {
+	fmt.Fprintf(b, ".TH %s 1 \"\" \"heroku-cli %s\" \"Heroku CLI Manual\"\n", strings.ToUpper(roffEscape(name)), roffEscape(Version))
+	fmt.Fprintf(b, ".SH NAME\n%s", roffEscape(name))
+	if description != "" {
+		fmt.Fprintf(b, " \\- %s", roffEscape(description))
+	}
+	b.WriteString("\n")
+}
+
+func manpageIndex(topics Topics) string {
+	var b bytes.Buffer
+	manpageHeader(&b, "heroku", "the Heroku command line interface")
+	b.WriteString(".SH SYNOPSIS\n.B heroku\n\\fICOMMAND\\fR [\\-\\-app \\fIAPP\\fR] [command\\-specific\\-options]\n")
+	b.WriteString(".SH DESCRIPTION\nThe Heroku CLI is used to manage Heroku apps from the command line.\n")
+	b.WriteString(".SH TOPICS\n")
+	for _, topic := range topics {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roffEscape(topic.Name), roffEscape(topic.Description))
+	}
+	manpageSeeAlso(&b, topicNames(topics))
+	return b.String()
+}
+
+func manpageTopic(topic *Topic, root *Command, commands Commands) string {
+	var b bytes.Buffer
+	manpageHeader(&b, manpageName(topic.Name), topic.Description)
+	if root != nil {
+		manpageCommandSections(&b, root)
+	} else {
+		fmt.Fprintf(&b, ".SH SYNOPSIS\n.B heroku %s:\\fICOMMAND\\fR\n", roffEscape(topic.Name))
+	}
+	if len(commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, command := range commands {
+			fmt.Fprintf(&b, ".TP\n.B heroku %s\n%s\n", roffEscape(CommandUsage(command)), roffEscape(command.Description))
+		}
+	}
+	seeAlso := []string{"heroku"}
+	for _, command := range commands {
+		seeAlso = append(seeAlso, command.String())
+	}
+	manpageSeeAlso(&b, seeAlso)
+	return b.String()
+}
+
+func manpageCommand(command *Command) string {
+	var b bytes.Buffer
+	manpageHeader(&b, manpageName(command.String()), command.Description)
+	manpageCommandSections(&b, command)
+	manpageSeeAlso(&b, []string{"heroku", command.Topic})
+	return b.String()
+}
+
+func manpageCommandSections(b *bytes.Buffer, command *Command) {
+	fmt.Fprintf(b, ".SH SYNOPSIS\n.B heroku %s\n", roffEscape(CommandUsage(command)))
+	if len(command.Aliases) > 0 {
+		fmt.Fprintf(b, ".SH ALIASES\n%s\n", roffEscape("heroku "+strings.Join(command.Aliases, ", heroku ")))
+	}
+	if command.Description != "" || command.Help != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		if command.Description != "" {
+			b.WriteString(roffEscape(command.Description) + "\n")
+		}
+		if command.Help != "" {
+			fmt.Fprintf(b, ".PP\n.nf\n%s\n.fi\n", roffEscape(strings.TrimSpace(command.Help)))
+		}
+	}
+	args := []Arg{}
+	for _, arg := range command.Args {
+		if !arg.Hidden {
+			args = append(args, arg)
+		}
+	}
+	if len(args) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, arg := range args {
+			optional := ""
+			if arg.Optional {
+				optional = "optional"
+			}
+			fmt.Fprintf(b, ".TP\n.I %s\n%s\n", roffEscape(strings.ToUpper(arg.Name)), optional)
+		}
+	}
+	options := []string{}
+	for _, flag := range command.allFlags() {
+		if flag.Hidden {
+			continue
+		}
+		options = append(options, fmt.Sprintf(".TP\n.B %s\n%s\n", roffEscape(strings.TrimSpace(flag.String())), roffEscape(flag.Description)))
+	}
+	if len(options) > 0 {
+		b.WriteString(".SH OPTIONS\n" + strings.Join(options, ""))
+	}
+}
+
+func manpageSeeAlso(b *bytes.Buffer, names []string) {
+	refs := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "heroku" {
+			refs = append(refs, "\\fBheroku\\fR(1)")
+		} else {
+			refs = append(refs, "\\fB"+roffEscape(manpageName(name))+"\\fR(1)")
+		}
+	}
+	fmt.Fprintf(b, ".SH SEE ALSO\n%s\n", strings.Join(refs, ", "))
+}
+
+func topicNames(topics Topics) []string {
+	names := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		names = append(names, topic.Name)
+	}
+	return names
+}
+
+// roffEscape escapes text so roff does not interpret it as requests or escapes
+func roffEscape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	s = strings.Replace(s, "-", `\-`, -1)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,53 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("manpages", func() {
+	var userPlugins *cli.Plugins
+	BeforeEach(func() {
+		userPlugins = cli.UserPlugins
+		cli.UserPlugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		dir := filepath.Join(cli.UserPlugins.Path, "executables", "heroku-xp")
+		os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "bin", "xp"), []byte("#!/bin/sh\n"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp",
+			"topics": [{"name": "xp", "description": "an installed plugin"}], "commands": [{"topic": "xp"}]
+		}`), 0644)
+		_, err := cli.UserPlugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(cli.UserPlugins.Path)
+		cli.UserPlugins = userPlugins
+	})
+
+	It("leaves out the commands of user plugins", func() {
+		Expect(cli.AllCommands().Find("xp")).NotTo(BeNil())
+		topics, commands := cli.ManpageSources()
+		Expect(topics.ByName("xp")).To(BeNil())
+		Expect(commands.Find("xp")).To(BeNil())
+		Expect(commands.Find("auth:login")).NotTo(BeNil())
+	})
+
+	It("renders the page of a command", func() {
+		_, commands := cli.ManpageSources()
+		page := cli.ManpageCommand(commands.Find("auth:login"))
+		Expect(page).To(HavePrefix(".TH HEROKU\\-AUTH\\-LOGIN 1 "))
+		Expect(page).To(ContainSubstring(".SH NAME\nheroku\\-auth\\-login \\- login with your Heroku credentials.\n"))
+		Expect(page).To(ContainSubstring(".SH SYNOPSIS\n.B heroku auth:login\n"))
+		Expect(page).To(ContainSubstring(".SH ALIASES\nheroku login\n"))
+		Expect(page).To(ContainSubstring(".SH OPTIONS\n.TP\n.B \\-\\-dry\\-run\n"))
+		Expect(page).To(ContainSubstring(".TP\n.B \\-\\-sso\nlogin for enterprise users under SSO\n"))
+		Expect(page).To(HaveSuffix(".SH SEE ALSO\n\\fBheroku\\fR(1), \\fBheroku\\-auth\\fR(1)\n"))
+	})
+})
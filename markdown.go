@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// commandsMarkdown writes a Markdown reference with a README.md index
+// and one file per topic documenting each of its commands
+func commandsMarkdown(ctx *Context) {
+	dir := ctx.Flags["dir"].(string)
+	must(os.MkdirAll(dir, 0755))
+	commands := AllCommands().Sort()
+	commands.loadUsages()
+	commands.loadFullHelp()
+	plugins := commandPlugins()
+	topics := AllTopics().NonHidden().Sort()
+	var index bytes.Buffer
+	index.WriteString("# Heroku CLI Command Reference\n\n")
+	fmt.Fprintf(&index, "Generated from heroku-cli/%s.\n\n", Version)
+	for _, topic := range topics {
+		topicCommands := Commands{}
+		for _, command := range commands.NonHidden() {
+			if command.Topic == topic.Name {
+				topicCommands = append(topicCommands, command)
+			}
+		}
+		if len(topicCommands) == 0 {
+			continue
+		}
+		fmt.Fprintf(&index, "* [%s](%s.md) - %s\n", topic.Name, topic.Name, topic.Description)
+		page := markdownTopic(topic, topicCommands, plugins)
+		must(ioutil.WriteFile(filepath.Join(dir, topic.Name+".md"), []byte(page), 0644))
+	}
+	must(ioutil.WriteFile(filepath.Join(dir, "README.md"), index.Bytes(), 0644))
+}
+
+func markdownTopic(topic *Topic, commands Commands, plugins map[string]*ManifestPlugin) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# heroku %s\n\n", topic.Name)
+	if topic.Description != "" {
+		b.WriteString(topic.Description + "\n\n")
+	}
+	if len(topic.Aliases) > 0 {
+		fmt.Fprintf(&b, "Aliases: `heroku %s`\n\n", strings.Join(topic.Aliases, "`, `heroku "))
+	}
+	for _, command := range commands {
+		fmt.Fprintf(&b, "* [heroku %s](#%s)\n", command.String(), markdownAnchor("heroku "+command.String()))
+	}
+	for _, command := range commands {
+		b.WriteString("\n" + markdownCommand(command, plugins))
+	}
+	return b.String()
+}
+
+func markdownCommand(command *Command, plugins map[string]*ManifestPlugin) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## heroku %s\n\n", command.String())
+	if command.Description != "" {
+		b.WriteString(command.Description + "\n\n")
+	}
+	plugin := "heroku-cli " + Version + " (built in)"
+	if command.Plugin != "" {
+		plugin = command.Plugin
+		if p := plugins[command.Plugin]; p != nil && p.Core {
+			plugin = p.Name + " " + p.Version + " (core)"
+		} else if p != nil {
+			plugin = p.Name + " " + p.Version + " (user)"
+		}
+	}
+	fmt.Fprintf(&b, "Plugin: %s\n\n", plugin)
+	if len(command.Aliases) > 0 {
+		fmt.Fprintf(&b, "Aliases: `heroku %s`\n\n", strings.Join(command.Aliases, "`, `heroku "))
+	}
+	fmt.Fprintf(&b, "```\nUsage: heroku %s\n\n%s\n```\n", command.Usage, command.FullHelp)
+	args := []string{}
+	for _, arg := range command.Args {
+		if arg.Hidden {
+			continue
+		}
+		line := "* `" + strings.ToUpper(arg.Name) + "`"
+		if arg.Optional {
+			line += " (optional)"
+		}
+		args = append(args, line)
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(&b, "\nArguments:\n\n%s\n", strings.Join(args, "\n"))
+	}
+	return b.String()
+}
+
+// markdownAnchor is the anchor GitHub generates for a heading
+func markdownAnchor(heading string) string {
+	anchor := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, heading)
+	return anchor
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// multiAppTargetEnv is set for the CLI processes started by runForApps.
+// It replaces the apps given with --app or --apps-from so each process runs against one app.
+// BuildContext unsets it so the processes a command starts do not inherit it.
+const multiAppTargetEnv = "HEROKU_MULTI_APP_TARGET"
+
+// multiAppConcurrency is how many apps a command runs against at once.
+// It can be changed with HEROKU_APPS_CONCURRENCY.
+func multiAppConcurrency() int {
+	if n, err := strconv.Atoi(os.Getenv("HEROKU_APPS_CONCURRENCY")); err == nil && n > 0 {
+		return n
+	}
+	return 4
+}
+
+// runForApps runs the current command once for each app in its own CLI process.
+// The output of each app is shown once its command finishes, followed by a summary.
+// It exits with 1 if the command failed for any app.
+func runForApps(apps []string) {
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	failed := []string{}
+	sem := make(chan struct{}, multiAppConcurrency())
+	for _, app := range apps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(app string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var out bytes.Buffer
+			cmd := exec.Command(BinPath, unexpandedArgs[1:]...)
+			cmd.Env = append(os.Environ(), multiAppTargetEnv+"="+app)
+			if argsFromStdin != nil {
+				cmd.Stdin = bytes.NewReader(argsFromStdin)
+			}
+			cmd.Stdout = &out
+			cmd.Stderr = &out
+			code := 0
+			if err := cmd.Run(); err != nil {
+				if _, ok := err.(*exec.ExitError); ok {
+					code = getExitCode(err)
+				} else {
+					code = 1
+					out.WriteString(err.Error() + "\n")
+				}
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			status := green("done")
+			if code != 0 {
+				failed = append(failed, app)
+				status = red("failed with exit code " + strconv.Itoa(code))
+			}
+			Printf("=== %s %s\n", cyan(app), status)
+			Print(out.String())
+			if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+				Println()
+			}
+		}(app)
+	}
+	wg.Wait()
+	Printf("\nRan heroku %s on %d %s: %d succeeded, %d failed\n", Args[1], len(apps), plural("app", len(apps)), len(apps)-len(failed), len(failed))
+	if len(failed) > 0 {
+		Errln("Failed: " + strings.Join(failed, ", "))
+		Exit(1)
+	}
+}
+
+// readAppsFile reads app names from a file, one per line.
+// Blank lines and lines starting with # are skipped.
+func readAppsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	apps := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		apps = append(apps, line)
+	}
+	return apps, scanner.Err()
+}
+
+func appendUnique(arr []string, items ...string) []string {
+	for _, item := range items {
+		if !contains(arr, item) {
+			arr = append(arr, item)
+		}
+	}
+	return arr
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/exec"
@@ -89,6 +90,50 @@ func (p *Plugins) OutdatedPackages(names ...string) (map[string]string, error) {
 	return packages, nil
 }
 
+// ResolveVersion is the newest version of a package that matches a semver range or dist-tag
+func (p *Plugins) ResolveVersion(name, version string) (string, error) {
+	stdout, stderr, err := p.execNpm("view", name+"@"+version, "version", "--json")
+	if err != nil {
+		return "", errors.New(stderr)
+	}
+	var versions []string
+	if err := json.Unmarshal([]byte(stdout), &versions); err != nil {
+		var v string
+		if err := json.Unmarshal([]byte(stdout), &v); err == nil {
+			versions = []string{v}
+		}
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("No version of %s matches %s", name, version)
+	}
+	return versions[len(versions)-1], nil
+}
+
+// PackageIntegrities are the integrity hashes the registry has for a version of a package
+// in the formats npm records them in package.json: each hash in dist.integrity, and dist.shasum as sha1-BASE64
+func (p *Plugins) PackageIntegrities(name, version string) ([]string, error) {
+	stdout, stderr, err := p.execNpm("view", name+"@"+version, "dist", "--json")
+	if err != nil {
+		return nil, errors.New(stderr)
+	}
+	var dist struct {
+		Integrity string `json:"integrity"`
+		Shasum    string `json:"shasum"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &dist); err != nil {
+		return nil, fmt.Errorf("Could not read the integrity of %s@%s: %s", name, version, err)
+	}
+	integrities := strings.Fields(dist.Integrity)
+	if dist.Shasum != "" {
+		integrity, err := shasumIntegrity(dist.Shasum)
+		if err != nil {
+			return nil, err
+		}
+		integrities = append(integrities, integrity)
+	}
+	return integrities, nil
+}
+
 // ClearCache clears the npm cache
 func (p *Plugins) ClearCache() error {
 	cmd, err := p.npmCmd("cache", "clean")
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginInfo is what plugins --json and plugins:info show about a plugin.
+// Source is core, user or linked. Outdated is only set when the registry was checked
+// and Latest is set when it is true.
+type PluginInfo struct {
+	Name          string    `json:"name"`
+	Version       string    `json:"version"`
+	Source        string    `json:"source"`
+	Executable    bool      `json:"executable"`
+	Path          string    `json:"path"`
+	SymlinkTarget string    `json:"symlink_target,omitempty"`
+	Pin           string    `json:"pin,omitempty"`
+	Topics        []string  `json:"topics"`
+	Commands      []string  `json:"commands"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Outdated      *bool     `json:"outdated,omitempty"`
+	Latest        string    `json:"latest,omitempty"`
+}
+
+// PluginInfos are sorted by name
+type PluginInfos []*PluginInfo
+
+func (infos PluginInfos) Len() int {
+	return len(infos)
+}
+
+func (infos PluginInfos) Less(i, j int) bool {
+	return infos[i].Name < infos[j].Name
+}
+
+func (infos PluginInfos) Swap(i, j int) {
+	infos[i], infos[j] = infos[j], infos[i]
+}
+
+// Info is the metadata of an installed plugin, or nil if it is not installed.
+// checkOutdated checks the registry for a newer version.
+// Core plugins are updated with the CLI so they are never outdated.
+func (p *Plugins) Info(name string, checkOutdated bool) *PluginInfo {
+	plugin := p.ByName(name)
+	if plugin == nil {
+		return nil
+	}
+	var latest map[string]string
+	if checkOutdated {
+		latest = map[string]string{}
+		if p != CorePlugins {
+			latest = p.outdatedPlugins([]*Plugin{plugin})
+		}
+	}
+	return p.pluginInfo(plugin, latest)
+}
+
+// pluginInfo is the metadata of a plugin. latest has the versions the outdated plugins can update to,
+// or is nil if the registry was not checked.
+func (p *Plugins) pluginInfo(plugin *Plugin, latest map[string]string) *PluginInfo {
+	info := &PluginInfo{
+		Name:       plugin.Name,
+		Version:    plugin.Version,
+		Source:     "user",
+		Executable: plugin.Executable != "",
+		Path:       p.pluginDir(plugin),
+		Topics:     []string{},
+		Commands:   []string{},
+		UpdatedAt:  plugin.UpdatedAt,
+		Latest:     latest[plugin.Name],
+	}
+	if latest != nil {
+		outdated := info.Latest != ""
+		info.Outdated = &outdated
+	}
+	if path, err := filepath.Abs(info.Path); err == nil {
+		info.Path = path
+	}
+	switch {
+	case p == CorePlugins:
+		info.Source = "core"
+	case p.isPluginSymlinked(plugin.Name):
+		info.Source = "linked"
+		target, err := filepath.EvalSymlinks(info.Path)
+		WarnIfError(err)
+		info.SymlinkTarget = target
+	default:
+		info.Pin = p.pin(plugin.Name)
+	}
+	if plugin.Topic != nil {
+		info.Topics = append(info.Topics, plugin.Topic.Name)
+	}
+	for _, topic := range plugin.Topics {
+		if !contains(info.Topics, topic.Name) {
+			info.Topics = append(info.Topics, topic.Name)
+		}
+	}
+	for _, command := range plugin.Commands {
+		if command == nil {
+			continue
+		}
+		info.Commands = append(info.Commands, command.String())
+		if !contains(info.Topics, command.Topic) {
+			info.Topics = append(info.Topics, command.Topic)
+		}
+	}
+	return info
+}
+
+// pluginsJSON shows the metadata of the user and core plugins.
+// checkOutdated checks the registry for newer versions of the user plugins.
+func pluginsJSON(checkOutdated bool) {
+	infos := PluginInfos{}
+	user := UserPlugins.Plugins()
+	var latest, coreLatest map[string]string
+	if checkOutdated {
+		latest = UserPlugins.outdatedPlugins(user)
+		coreLatest = map[string]string{}
+	}
+	for _, plugin := range user {
+		infos = append(infos, UserPlugins.pluginInfo(plugin, latest))
+	}
+	for _, plugin := range CorePlugins.Plugins() {
+		infos = append(infos, CorePlugins.pluginInfo(plugin, coreLatest))
+	}
+	sort.Stable(infos)
+	data, err := json.MarshalIndent(infos, "", "  ")
+	must(err)
+	Println(string(data))
+}
+
+func pluginsInfo(ctx *Context) {
+	name, _ := splitPluginVersion(ctx.Args.(map[string]string)["name"])
+	checkOutdated := ctx.Flags["outdated"] == true
+	info := UserPlugins.Info(name, checkOutdated)
+	if info == nil {
+		info = CorePlugins.Info(name, checkOutdated)
+	}
+	if info == nil {
+		ExitWithMessage("%s is not installed", name)
+		return
+	}
+	if ctx.Flags["json"] == true {
+		data, err := json.MarshalIndent(info, "", "  ")
+		must(err)
+		Println(string(data))
+		return
+	}
+	Printf("=== %s %s\n", cyan(info.Name), info.Version)
+	Printf("Source:   %s\n", info.Source)
+	Printf("Path:     %s\n", info.Path)
+	if info.SymlinkTarget != "" {
+		Printf("Target:   %s\n", info.SymlinkTarget)
+	}
+	if info.Pin != "" {
+		Printf("Pinned:   %s\n", info.Pin)
+	}
+	if !info.UpdatedAt.IsZero() {
+		Printf("Updated:  %s\n", info.UpdatedAt.Format(time.RFC3339))
+	}
+	switch {
+	case info.Outdated == nil:
+	case *info.Outdated:
+		Printf("Outdated: %s\n", yellow(info.Latest+" is available"))
+	default:
+		Printf("Outdated: no\n")
+	}
+	Printf("Topics:   %s\n", strings.Join(info.Topics, ", "))
+	Printf("Commands: %s\n", strings.Join(info.Commands, ", "))
+}
@@ -28,10 +28,14 @@ func init() {
 				DisableAnalytics: true,
 				Flags: []Flag{
 					{Name: "core", Description: "show core plugins", Hidden: true},
+					{Name: "json", Description: "show user and core plugins with their metadata as json"},
+					{Name: "outdated", Description: "check the registry for newer versions of the plugins in the json"},
 				},
 				Help: `
 Example:
-  $ heroku plugins`,
+  $ heroku plugins
+  $ heroku plugins --json
+  $ heroku plugins --json --outdated`,
 
 				Run: pluginsList,
 			},
@@ -42,9 +46,13 @@ Example:
 				VariableArgs: true,
 				Description:  "Installs a plugin into the CLI",
 				Help: `Install a Heroku plugin
+  Plugins are npm packages, or directories with an executable and a heroku-plugin.json manifest.
+  Arguments starting with @ are read from a file, so scoped packages start with @@.
 
   Example:
-  $ heroku plugins:install heroku-production-status`,
+  $ heroku plugins:install heroku-production-status
+  $ heroku plugins:install @@acme/heroku-tools
+  $ heroku plugins:install ./my-tool`,
 
 				Run: pluginsInstall,
 			},
@@ -67,20 +75,129 @@ Example:
 				Topic:       "plugins",
 				Command:     "uninstall",
 				Hidden:      true,
+				Dangerous:   true,
+				ConfirmWith: "name",
 				Args:        []Arg{{Name: "name"}},
 				Description: "Uninstalls a plugin from the CLI",
 				Help: `Uninstalls a Heroku plugin
 
   Example:
-  $ heroku plugins:uninstall heroku-production-status`,
+  $ heroku plugins:uninstall heroku-production-status --confirm heroku-production-status`,
 
 				Run: pluginsUninstall,
 			},
+			{
+				Topic:       "plugins",
+				Command:     "info",
+				Description: "Shows the metadata of a plugin",
+				Args:        []Arg{{Name: "name"}},
+				Flags: []Flag{
+					{Name: "json", Description: "output in json format"},
+					{Name: "outdated", Description: "check the registry for a newer version"},
+				},
+				Help: `Shows where a plugin comes from and is installed and the topics and commands it adds.
+  With --outdated it also checks whether a newer version is available.
+
+  Example:
+  $ heroku plugins:info heroku-production-status --outdated`,
+
+				Run: pluginsInfo,
+			},
+			{
+				Topic:       "plugins",
+				Command:     "update",
+				Description: "Updates a plugin",
+				Args:        []Arg{{Name: "name"}},
+				Flags: []Flag{
+					{Name: "to", HasValue: true, Description: "version, semver range or dist-tag to update to and pin the plugin to"},
+				},
+				Help: `Updates a plugin to the newest version within its pin, or within --to.
+  Plugins installed with name@version are pinned to that version or range
+  and are only updated within it. Use --to latest to unpin a plugin.
+
+  Example:
+  $ heroku plugins:update heroku-production-status
+  $ heroku plugins:update heroku-production-status --to ^2.0.0`,
+
+				Run: pluginsUpdate,
+			},
+			{
+				Topic:       "plugins",
+				Command:     "rollback",
+				Description: "Restores the previous version of a plugin",
+				Args:        []Arg{{Name: "name"}},
+				Help: `Reinstalls the version of a plugin that was installed before the last update
+  and pins the plugin to it. Use plugins:update --to latest to unpin it again.
+
+  Example:
+  $ heroku plugins:rollback heroku-production-status`,
+
+				Run: pluginsRollback,
+			},
+			{
+				Topic:            "plugins",
+				Command:          "conflicts",
+				Description:      "Lists commands that are in more than one plugin",
+				DisableAnalytics: true,
+				Help: `Shows the plugins for each command name that more than one plugin uses,
+  and which of them runs. Choose the plugin that runs with plugins:prefer.
+
+  Example:
+  $ heroku plugins:conflicts`,
+
+				Run: pluginsConflicts,
+			},
+			{
+				Topic:            "plugins",
+				Command:          "prefer",
+				Description:      "Chooses the plugin that runs a command",
+				Args:             []Arg{{Name: "command"}, {Name: "plugin", Optional: true}},
+				DisableAnalytics: true,
+				Help: `Runs the command from PLUGIN when more than one plugin has it.
+  Use heroku-cli as PLUGIN for commands built into the CLI.
+  Without PLUGIN the preference is removed.
+  Preferences are stored in config.json as plugin_precedence.
+
+  Example:
+  $ heroku plugins:prefer apps:info heroku-apps`,
+
+				Run: pluginsPrefer,
+			},
+			{
+				Topic:       "plugins",
+				Command:     "export",
+				Description: "Writes the plugins lockfile",
+				Args:        []Arg{{Name: "file", Optional: true}},
+				Help: `Writes the exact versions and integrity hashes of the installed plugins
+  to FILE, or to stdout. Install the same plugins elsewhere with plugins:import.
+
+  Example:
+  $ heroku plugins:export heroku-plugins.lock.json`,
+
+				Run: pluginsExport,
+			},
+			{
+				Topic:       "plugins",
+				Command:     "import",
+				Description: "Installs the plugins in a lockfile",
+				Args:        []Arg{{Name: "file"}},
+				Help: `Installs the exact versions of the plugins in a lockfile from plugins:export.
+  Plugins that do not match their integrity hash are not installed.
+
+  Example:
+  $ heroku plugins:import heroku-plugins.lock.json`,
+
+				Run: pluginsImport,
+			},
 		},
 	})
 }
 
 func pluginsList(ctx *Context) {
+	if ctx.Flags["json"] == true {
+		pluginsJSON(ctx.Flags["outdated"] == true)
+		return
+	}
 	var names []string
 	for _, plugin := range UserPlugins.Plugins() {
 		symlinked := ""
@@ -111,7 +228,17 @@ func pluginsInstall(ctx *Context) {
 	toinstall := make([]string, 0, len(plugins))
 	core := CorePlugins.PluginNames()
 	for _, plugin := range plugins {
-		if contains(core, strings.Split(plugin, "@")[0]) {
+		if isExecutablePluginDir(plugin) {
+			var installed *Plugin
+			action("Installing plugin "+plugin, "done", func() {
+				var err error
+				installed, err = UserPlugins.installExecutablePlugin(plugin)
+				must(err)
+			})
+			warnCommandConflicts(installed.Name)
+			continue
+		}
+		if name, _ := splitPluginVersion(plugin); contains(core, name) {
 			Warn("Not installing " + plugin + " because it is already installed as a core plugin.")
 			continue
 		}
@@ -129,6 +256,10 @@ func pluginsInstall(ctx *Context) {
 			must(err)
 		}
 	})
+	for _, plugin := range toinstall {
+		name, _ := splitPluginVersion(plugin)
+		warnCommandConflicts(name)
+	}
 }
 
 func pluginsLink(ctx *Context) {
@@ -140,7 +271,18 @@ func pluginsLink(ctx *Context) {
 	must(err)
 	_, err = os.Stat(path)
 	must(err)
+	if isExecutablePluginDir(path) {
+		var linked *Plugin
+		action("Symlinking "+filepath.Base(path), "done", func() {
+			var err error
+			linked, err = UserPlugins.linkExecutablePlugin(path)
+			must(err)
+		})
+		warnCommandConflicts(linked.Name)
+		return
+	}
 	name := filepath.Base(path)
+	var linked *Plugin
 	action("Symlinking "+name, "done", func() {
 		newPath := UserPlugins.pluginPath(name)
 		os.Remove(newPath)
@@ -148,29 +290,145 @@ func pluginsLink(ctx *Context) {
 		os.MkdirAll(filepath.Dir(newPath), 0755)
 		err = os.Symlink(path, newPath)
 		must(err)
-		plugin, err := UserPlugins.ParsePlugin(name)
+		linked, err = UserPlugins.ParsePlugin(name)
 		must(err)
-		if name != plugin.Name {
+		if name != linked.Name {
 			path = newPath
-			newPath = UserPlugins.pluginPath(plugin.Name)
+			newPath = UserPlugins.pluginPath(linked.Name)
 			os.Remove(newPath)
 			os.RemoveAll(newPath)
+			os.MkdirAll(filepath.Dir(newPath), 0755)
 			os.Rename(path, newPath)
 		}
 	})
+	warnCommandConflicts(linked.Name)
 }
 
 func pluginsUninstall(ctx *Context) {
-	name := ctx.Args.(map[string]string)["name"]
+	name, _ := splitPluginVersion(ctx.Args.(map[string]string)["name"])
 	if !contains(UserPlugins.PluginNames(), name) {
 		ExitWithMessage("%s is not installed", name)
 	}
 	Errf("Uninstalling plugin %s...", name)
-	must(UserPlugins.RemovePackages(name))
+	if plugin := UserPlugins.ByName(name); plugin.Executable != "" {
+		must(os.RemoveAll(UserPlugins.pluginDir(plugin)))
+	} else {
+		must(UserPlugins.RemovePackages(name))
+	}
 	UserPlugins.removeFromCache(name)
+	must(UserPlugins.saveLock())
 	Errln(" done")
 }
 
+func pluginsExport(ctx *Context) {
+	lock, err := UserPlugins.Lock()
+	must(err)
+	for i := range lock.Plugins {
+		lock.Plugins[i].Previous = nil
+	}
+	path := ctx.Args.(map[string]string)["file"]
+	if path == "" {
+		data, err := json.MarshalIndent(lock, "", "  ")
+		must(err)
+		Println(string(data))
+		return
+	}
+	must(saveJSON(lock, path))
+	Errf("Exported %d %s to %s\n", len(lock.Plugins), plural("plugin", len(lock.Plugins)), path)
+}
+
+func pluginsImport(ctx *Context) {
+	path := ctx.Args.(map[string]string)["file"]
+	var lock PluginLock
+	if err := readJSON(&lock, path); err != nil {
+		ExitWithMessage("Error reading %s\n%s", path, err)
+	}
+	if lock.Version != PluginLockVersion {
+		ExitWithMessage("%s is a version %d lockfile. This CLI reads version %d.", path, lock.Version, PluginLockVersion)
+	}
+	core := CorePlugins.PluginNames()
+	for _, locked := range lock.Plugins {
+		switch {
+		case UserPlugins.isPluginSymlinked(locked.Name):
+			Warn("Not installing " + locked.Name + " because it is symlinked.")
+		case UserPlugins.isInstalled(locked):
+			// already installed
+		case locked.Executable:
+			Warn(locked.Name + " " + locked.Version + " is an executable plugin. Install it from its directory with heroku plugins:install DIRECTORY")
+		case contains(core, locked.Name):
+			Warn("Not installing " + locked.Name + " because it is already installed as a core plugin.")
+		default:
+			locked := locked
+			action("Installing plugin "+locked.Name+"@"+locked.Version, "done", func() {
+				err := UserPlugins.installLockedPlugin(locked)
+				WarnIfError(UserPlugins.saveLock())
+				must(err)
+			})
+		}
+		if !locked.Executable && UserPlugins.ByName(locked.Name) != nil {
+			must(UserPlugins.setPin(locked.Name, locked.Pin))
+		}
+	}
+	must(UserPlugins.saveLock())
+}
+
+func pluginsUpdate(ctx *Context) {
+	name := ctx.Args.(map[string]string)["name"]
+	plugin := UserPlugins.ByName(name)
+	switch {
+	case plugin == nil:
+		ExitWithMessage("%s is not installed", name)
+	case UserPlugins.isPluginSymlinked(name):
+		ExitWithMessage("%s is symlinked", name)
+	case plugin.Executable != "":
+		ExitWithMessage("%s is an executable plugin. Update it with heroku plugins:install DIRECTORY", name)
+	}
+	pin := UserPlugins.pin(name)
+	if to, ok := ctx.Flags["to"].(string); ok {
+		pin = to
+		if pin == "latest" {
+			pin = ""
+		}
+	}
+	version, err := UserPlugins.ResolveVersion(name, pinOrLatest(pin))
+	if err != nil {
+		ExitWithMessage("%s", err)
+	}
+	if version != plugin.Version {
+		action("Updating "+name+" from "+plugin.Version+" to "+version, "done", func() {
+			UserPlugins.lockPlugin(name)
+			defer UserPlugins.unlockPlugin(name)
+			must(UserPlugins.installPackages(name + "@" + version))
+			_, err := UserPlugins.ParsePlugin(name)
+			must(err)
+		})
+	} else {
+		Errf("%s is already at %s\n", name, version)
+	}
+	must(UserPlugins.setPin(name, pin))
+}
+
+func pluginsRollback(ctx *Context) {
+	name := ctx.Args.(map[string]string)["name"]
+	locked := UserPlugins.readLock().Plugins.ByName(name)
+	switch {
+	case UserPlugins.ByName(name) == nil:
+		ExitWithMessage("%s is not installed", name)
+	case locked == nil || locked.Previous == nil:
+		ExitWithMessage("There is no previous version of %s to roll back to", name)
+	case locked.Executable || locked.Previous.Executable:
+		ExitWithMessage("%s is an executable plugin. Install the previous version with heroku plugins:install DIRECTORY", name)
+	}
+	previous := *locked.Previous
+	action("Rolling back "+name+" from "+locked.Version+" to "+previous.Version, "done", func() {
+		err := UserPlugins.installLockedPlugin(previous)
+		WarnIfError(UserPlugins.saveLock())
+		must(err)
+	})
+	must(UserPlugins.setPin(name, previous.Version))
+	Errf("%s is pinned to %s. Unpin it with %s\n", name, previous.Version, cyan("heroku plugins:update "+name+" --to latest"))
+}
+
 // Plugins represents either core or user plugins
 type Plugins struct {
 	Path    string
@@ -183,21 +441,26 @@ var CorePlugins = &Plugins{Path: filepath.Join(AppDir, "lib")}
 // UserPlugins are user-installable plugins
 var UserPlugins = &Plugins{Path: filepath.Join(DataHome, "plugins")}
 
-// Plugin represents a javascript plugin
+// Plugin represents a javascript plugin or, when Executable is set, an executable plugin
 type Plugin struct {
-	Name      string    `json:"name"`
-	Version   string    `json:"version"`
-	Topics    Topics    `json:"topics"`
-	Topic     *Topic    `json:"topic"`
-	Commands  Commands  `json:"commands"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Name       string    `json:"name"`
+	Version    string    `json:"version"`
+	Executable string    `json:"executable,omitempty"`
+	Topics     Topics    `json:"topics"`
+	Topic      *Topic    `json:"topic"`
+	Commands   Commands  `json:"commands"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
 // Commands lists all the commands of the plugins
 func (p *Plugins) Commands() (commands Commands) {
 	for _, plugin := range p.Plugins() {
 		for _, command := range plugin.Commands {
-			command.Run = p.runFn(plugin, command.Topic, command.Command)
+			if plugin.Executable != "" {
+				command.Run = p.runExecutableFn(plugin, command)
+			} else {
+				command.Run = p.runFn(plugin, command.Topic, command.Command)
+			}
 			commands = append(commands, command)
 		}
 	}
@@ -343,12 +606,13 @@ func (p *Plugins) PluginNamesNotSymlinked() []string {
 }
 
 func (p *Plugins) isPluginSymlinked(plugin string) bool {
-	path := filepath.Join(p.modulesPath(), plugin)
-	fi, err := os.Lstat(path)
-	if err != nil {
-		return false
+	for _, dir := range []string{p.modulesPath(), p.executablesPath()} {
+		fi, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(plugin)))
+		if err == nil {
+			return fi.Mode()&os.ModeSymlink != 0
+		}
 	}
-	return fi.Mode()&os.ModeSymlink != 0
+	return false
 }
 
 func contains(arr []string, s string) bool {
@@ -360,35 +624,63 @@ func contains(arr []string, s string) bool {
 	return false
 }
 
-// InstallPlugins installs plugins
-func (p *Plugins) InstallPlugins(names ...string) error {
-	for _, name := range names {
+// InstallPlugins installs plugins.
+// Plugins given as name@version are pinned to that version or semver range.
+func (p *Plugins) InstallPlugins(plugins ...string) error {
+	for _, plugin := range plugins {
+		name, _ := splitPluginVersion(plugin)
 		p.lockPlugin(name)
+		defer p.unlockPlugin(name)
 	}
-	defer func() {
-		for _, name := range names {
-			p.unlockPlugin(name)
-		}
-	}()
-	err := p.installPackages(names...)
+	err := p.installPackages(plugins...)
 	if err != nil {
 		return err
 	}
-	for _, name := range names {
+	for _, plugin := range plugins {
+		name, _ := splitPluginVersion(plugin)
 		_, err := p.ParsePlugin(name)
 		must(err)
 	}
+	if err := p.saveLock(); err != nil {
+		return err
+	}
+	for _, plugin := range plugins {
+		name, version := splitPluginVersion(plugin)
+		if version == "latest" {
+			version = ""
+		}
+		if err := p.setPin(name, version); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// directory location of plugin
+// splitPluginVersion splits name@version into the plugin name and the version, which may be empty.
+// Scoped packages start with @, as in @acme/heroku-tools@1.2.0.
+func splitPluginVersion(plugin string) (string, string) {
+	if i := strings.LastIndex(plugin, "@"); i > 0 {
+		return plugin[:i], plugin[i+1:]
+	}
+	return plugin, ""
+}
+
+// pinOrLatest is the version to update a plugin pinned to pin to
+func pinOrLatest(pin string) string {
+	if pin == "" {
+		return "latest"
+	}
+	return pin
+}
+
+// directory location of plugin, which is in a scope directory for scoped packages
 func (p *Plugins) pluginPath(plugin string) string {
-	return filepath.Join(p.Path, "node_modules", plugin)
+	return filepath.Join(p.Path, "node_modules", filepath.FromSlash(plugin))
 }
 
-// name of lockfile
+// name of lockfile, with the / of scoped packages escaped
 func (p *Plugins) lockfile(name string) string {
-	return filepath.Join(p.Path, name+".updating")
+	return filepath.Join(p.Path, strings.Replace(name, "/", "%2F", -1)+".updating")
 }
 
 // lock a plugin for reading
@@ -414,12 +706,7 @@ func (p *Plugins) unlockPlugin(name string) {
 
 // Update updates the plugins
 func (p *Plugins) Update() {
-	plugins := p.PluginNamesNotSymlinked()
-	if len(plugins) == 0 {
-		return
-	}
-	packages, err := p.OutdatedPackages(plugins...)
-	WarnIfError(err)
+	packages := p.outdatedPlugins(p.Plugins())
 	if len(packages) > 0 {
 		action("heroku-cli: Updating plugins", "", func() {
 			for name, version := range packages {
@@ -429,11 +716,42 @@ func (p *Plugins) Update() {
 				WarnIfError(err)
 				p.unlockPlugin(name)
 			}
+			WarnIfError(p.saveLock())
 		})
 		Errf(" done. Updated %d %s.\n", len(packages), plural("package", len(packages)))
 	}
 }
 
+// outdatedPlugins are the versions the npm plugins can be updated to, within their pins.
+// Symlinked and executable plugins are not updated.
+func (p *Plugins) outdatedPlugins(plugins []*Plugin) map[string]string {
+	latest := map[string]string{}
+	unpinned := []string{}
+	for _, plugin := range plugins {
+		if plugin.Executable != "" || p.isPluginSymlinked(plugin.Name) {
+			continue
+		}
+		pin := p.pin(plugin.Name)
+		if pin == "" {
+			unpinned = append(unpinned, plugin.Name)
+			continue
+		}
+		version, err := p.ResolveVersion(plugin.Name, pin)
+		WarnIfError(err)
+		if err == nil && version != plugin.Version {
+			latest[plugin.Name] = version
+		}
+	}
+	if len(unpinned) > 0 {
+		outdated, err := p.OutdatedPackages(unpinned...)
+		WarnIfError(err)
+		for name, version := range outdated {
+			latest[name] = version
+		}
+	}
+	return latest
+}
+
 // MigrateRubyPlugins migrates from legacy ruby plugins to node versions
 func (p *Plugins) MigrateRubyPlugins() {
 	pluginMap := map[string]string{
@@ -490,6 +808,7 @@ func (p *Plugins) saveCache() {
 	if err := saveJSON(p.plugins, p.cachePath()); err != nil {
 		must(err)
 	}
+	os.Remove(conflictsCachePath())
 }
 
 // Plugins reads the cache file into the struct
@@ -514,7 +833,7 @@ func (p *Plugins) Plugins() []*Plugin {
 
 func (p *Plugins) removeMissingPlugins() {
 	for i, plugin := range p.plugins {
-		if exists, _ := FileExists(p.pluginPath(plugin.Name)); !exists {
+		if exists, _ := FileExists(p.pluginDir(plugin)); !exists {
 			p.plugins = append(p.plugins[:i], p.plugins[i+1:]...)
 			p.saveCache()
 			p.removeMissingPlugins()
@@ -560,7 +879,12 @@ func (p *Plugins) RefreshPlugins() {
 			continue
 		}
 		action(fmt.Sprintf("Parsing %s", plugin.Name), "done", func() {
-			_, err := p.ParsePlugin(plugin.Name)
+			var err error
+			if plugin.Executable != "" {
+				_, err = p.ParseExecutablePlugin(plugin.Name)
+			} else {
+				_, err = p.ParsePlugin(plugin.Name)
+			}
 			must(err)
 		})
 	}
@@ -571,7 +895,7 @@ func (p *Plugins) pluginRefreshNeeded(plugin *Plugin) bool {
 	if !p.isPluginSymlinked(plugin.Name) {
 		return false
 	}
-	base, err := filepath.EvalSymlinks(p.pluginPath(plugin.Name))
+	base, err := filepath.EvalSymlinks(p.pluginDir(plugin))
 	must(err)
 	skip := func(path string) bool {
 		for _, dir := range []string{".git", "node_modules"} {
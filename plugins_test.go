@@ -0,0 +1,573 @@
+package main_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	cli "github.com/heroku/cli"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("executable plugins", func() {
+	var plugins *cli.Plugins
+	pluginDir := filepath.Join("tmp", "plugins", "executables", "heroku-xp")
+	BeforeEach(func() {
+		plugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		os.MkdirAll(filepath.Join(pluginDir, "bin"), 0755)
+		ioutil.WriteFile(filepath.Join(pluginDir, "bin", "xp"), []byte("#!/bin/sh\n"), 0755)
+	})
+	AfterEach(func() {
+		os.RemoveAll(filepath.Join("tmp", "plugins"))
+	})
+
+	It("reads commands from the manifest", func() {
+		ioutil.WriteFile(filepath.Join(pluginDir, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp",
+			"commands": [{"topic": "xp", "command": "hello", "help": "  says hello\n"}]
+		}`), 0644)
+		plugin, err := plugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(plugin.Executable).To(Equal("bin/xp"))
+		Expect(plugin.Commands[0].Plugin).To(Equal("heroku-xp"))
+		Expect(plugin.Commands[0].Help).To(Equal("says hello"))
+		commands := plugins.Commands()
+		Expect(commands).To(HaveLen(1))
+		Expect(commands[0].Run).NotTo(BeNil())
+	})
+
+	It("requires the executable to exist", func() {
+		ioutil.WriteFile(filepath.Join(pluginDir, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "executable": "bin/missing",
+			"commands": [{"topic": "xp", "command": "hello"}]
+		}`), 0644)
+		_, err := plugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("scoped plugins", func() {
+	plugins := &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+
+	It("splits the version from the name", func() {
+		name, version := cli.SplitPluginVersion("@acme/heroku-tools@1.2.0")
+		Expect(name).To(Equal("@acme/heroku-tools"))
+		Expect(version).To(Equal("1.2.0"))
+		name, version = cli.SplitPluginVersion("@acme/heroku-tools")
+		Expect(name).To(Equal("@acme/heroku-tools"))
+		Expect(version).To(Equal(""))
+		name, version = cli.SplitPluginVersion("heroku-tools@^2.0.0")
+		Expect(name).To(Equal("heroku-tools"))
+		Expect(version).To(Equal("^2.0.0"))
+	})
+
+	It("are installed in a directory for their scope", func() {
+		Expect(plugins.PluginPath("@acme/heroku-tools")).To(Equal(filepath.Join("tmp", "plugins", "node_modules", "@acme", "heroku-tools")))
+	})
+
+	It("escape the / in the name of their lockfile", func() {
+		Expect(plugins.Lockfile("@acme/heroku-tools")).To(Equal(filepath.Join("tmp", "plugins", "@acme%2Fheroku-tools.updating")))
+	})
+})
+
+var _ = Describe("installing executable plugins", func() {
+	var plugins, userPlugins *cli.Plugins
+	var src, out string
+	var exitCode int
+	writeManifest := func(name string) {
+		ioutil.WriteFile(filepath.Join(src, "heroku-plugin.json"), []byte(`{
+			"name": "`+name+`", "version": "1.0.0", "executable": "bin/xp",
+			"commands": [{"topic": "xp", "command": "hello", "args": [{"name": "name"}]}]
+		}`), 0644)
+	}
+	BeforeEach(func() {
+		plugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		src, _ = filepath.Abs(filepath.Join("tmp", "src", "heroku-xp"))
+		out, _ = filepath.Abs(filepath.Join("tmp", "src", "out"))
+		os.MkdirAll(filepath.Join(src, "bin"), 0755)
+		os.MkdirAll(out, 0755)
+		ioutil.WriteFile(filepath.Join(src, "bin", "xp"), []byte(`#!/bin/sh
+echo "$@" > `+out+`/args
+echo "$HEROKU_CONTEXT_FD" > `+out+`/fd
+cat <&3 > `+out+`/context.json
+exit 3
+`), 0755)
+		writeManifest("heroku-xp")
+		userPlugins = cli.UserPlugins
+		cli.UserPlugins = plugins
+		cli.ExitFn = func(code int) { exitCode = code }
+	})
+	AfterEach(func() {
+		cli.ExitFn = func(int) {}
+		cli.UserPlugins = userPlugins
+		os.RemoveAll(filepath.Join("tmp", "plugins"))
+		os.RemoveAll(filepath.Join("tmp", "src"))
+	})
+
+	It("copies the plugin directory", func() {
+		plugin, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(plugin.Name).To(Equal("heroku-xp"))
+		fi, err := os.Lstat(filepath.Join("tmp", "plugins", "executables", "heroku-xp"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fi.IsDir()).To(BeTrue())
+		Expect(filepath.Join("tmp", "plugins", "executables", "heroku-xp", "bin", "xp")).To(BeAnExistingFile())
+		Expect(plugins.PluginNames()).To(Equal([]string{"heroku-xp"}))
+	})
+
+	It("symlinks the plugin directory", func() {
+		_, err := plugins.LinkExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		target, err := os.Readlink(filepath.Join("tmp", "plugins", "executables", "heroku-xp"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(target).To(Equal(src))
+	})
+
+	It("refuses names that are not plugin names", func() {
+		kept := filepath.Join("tmp", "plugins", "kept")
+		os.MkdirAll(kept, 0755)
+		for _, name := range []string{"../kept", "a/b", "@acme/../../kept", "..", "Heroku XP"} {
+			writeManifest(name)
+			_, err := plugins.InstallExecutablePlugin(src)
+			Expect(err).To(MatchError("Invalid plugin. " + name + " is not a valid plugin name."))
+			_, err = plugins.LinkExecutablePlugin(src)
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(kept).To(BeADirectory())
+	})
+
+	It("refuses executables outside the plugin directory", func() {
+		for _, executable := range []string{"../../../../bin/sh", "/bin/sh", "bin/../../heroku-other/bin/xp"} {
+			ioutil.WriteFile(filepath.Join(src, "heroku-plugin.json"), []byte(`{
+				"name": "heroku-xp", "version": "1.0.0", "executable": "`+executable+`", "commands": [{"topic": "xp"}]
+			}`), 0644)
+			_, err := plugins.InstallExecutablePlugin(src)
+			Expect(err).To(MatchError("Invalid plugin. The executable " + executable + " is not in the plugin directory."))
+		}
+	})
+
+	It("copies symlinks without following them", func() {
+		os.Symlink(out, filepath.Join(src, "linked"))
+		_, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		fi, err := os.Lstat(filepath.Join("tmp", "plugins", "executables", "heroku-xp", "linked"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fi.Mode() & os.ModeSymlink).NotTo(BeZero())
+	})
+
+	It("shows an error when the executable cannot be run", func() {
+		_, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		os.Chmod(filepath.Join("tmp", "plugins", "executables", "heroku-xp", "bin", "xp"), 0644)
+		cli.Start("heroku", "xp:hello", "world")
+		Expect(exitCode).To(Equal(2))
+		Expect(stderr()).To(HavePrefix(" !    Could not run heroku xp:hello from the plugin heroku-xp.\n"))
+	})
+
+	It("only uninstalls with --confirm when not interactive", func() {
+		_, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		cli.Start("heroku", "plugins:uninstall", "heroku-xp")
+		Expect(exitCode).To(Equal(1))
+		Expect(stderr()).To(Equal(" !    heroku plugins:uninstall is destructive. To proceed, run it again with --confirm heroku-xp\n"))
+		Expect(plugins.PluginNames()).To(Equal([]string{"heroku-xp"}))
+		cli.Start("heroku", "plugins:uninstall", "heroku-xp", "--confirm", "heroku-xp")
+		Expect(plugins.PluginNames()).To(BeEmpty())
+	})
+
+	It("only runs a dry run of a command that supports it", func() {
+		_, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		os.Setenv("HEROKU_DRY_RUN", "1")
+		defer os.Unsetenv("HEROKU_DRY_RUN")
+		cli.Start("heroku", "xp:hello", "world")
+		Expect(exitCode).To(Equal(2))
+		Expect(filepath.Join(out, "args")).NotTo(BeAnExistingFile())
+		ioutil.WriteFile(filepath.Join(src, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp",
+			"commands": [{"topic": "xp", "command": "hello", "supportsDryRun": true}]
+		}`), 0644)
+		_, err = plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		cli.Start("heroku", "xp:hello")
+		var ctx cli.Context
+		data, _ := ioutil.ReadFile(filepath.Join(out, "context.json"))
+		Expect(json.Unmarshal(data, &ctx)).To(Succeed())
+		Expect(ctx.DryRun).To(BeTrue())
+	})
+
+	It("runs a command with the context on fd 3 and exits with its exit code", func() {
+		_, err := plugins.InstallExecutablePlugin(src)
+		Expect(err).ShouldNot(HaveOccurred())
+		cli.Start("heroku", "xp:hello", "world")
+		Expect(exitCode).To(Equal(3))
+		args, _ := ioutil.ReadFile(filepath.Join(out, "args"))
+		Expect(string(args)).To(Equal("xp:hello world\n"))
+		fd, _ := ioutil.ReadFile(filepath.Join(out, "fd"))
+		Expect(string(fd)).To(Equal("3\n"))
+		var ctx cli.Context
+		data, _ := ioutil.ReadFile(filepath.Join(out, "context.json"))
+		Expect(json.Unmarshal(data, &ctx)).To(Succeed())
+		Expect(ctx.Command.String()).To(Equal("xp:hello"))
+		Expect(ctx.Args).To(Equal(map[string]interface{}{"name": "world"}))
+	})
+})
+
+var _ = Describe("plugins lockfile", func() {
+	var plugins *cli.Plugins
+	npmDir := filepath.Join("tmp", "plugins", "node_modules", "heroku-npm")
+	executableDir := filepath.Join("tmp", "plugins", "executables", "heroku-xp")
+	BeforeEach(func() {
+		plugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		os.MkdirAll(npmDir, 0755)
+		ioutil.WriteFile(filepath.Join(npmDir, "package.json"), []byte(`{
+			"name": "heroku-npm", "version": "2.1.0", "_shasum": "0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"
+		}`), 0644)
+		os.MkdirAll(filepath.Join(executableDir, "bin"), 0755)
+		ioutil.WriteFile(filepath.Join(executableDir, "bin", "xp"), []byte("#!/bin/sh\n"), 0755)
+		ioutil.WriteFile(filepath.Join("tmp", "plugins", "plugins.json"), []byte(`[
+			{"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp", "commands": [{"topic": "xp"}]},
+			{"name": "heroku-npm", "version": "2.1.0", "commands": [{"topic": "npm"}]}
+		]`), 0644)
+	})
+	AfterEach(func() {
+		os.RemoveAll(filepath.Join("tmp", "plugins"))
+	})
+
+	It("records versions and integrity hashes sorted by name", func() {
+		lock, err := plugins.Lock()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(lock.Version).To(Equal(cli.PluginLockVersion))
+		Expect(lock.Plugins).To(HaveLen(2))
+		Expect(lock.Plugins[0]).To(Equal(cli.LockedPlugin{Name: "heroku-npm", Version: "2.1.0", Integrity: "sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM="}))
+		Expect(lock.Plugins[1].Name).To(Equal("heroku-xp"))
+		Expect(lock.Plugins[1].Executable).To(BeTrue())
+		Expect(lock.Plugins[1].Integrity).To(HavePrefix("sha256-"))
+	})
+
+	It("keeps the pin and records the previous version when a plugin changes version", func() {
+		ioutil.WriteFile(filepath.Join("tmp", "plugins", "plugins.lock.json"), []byte(`{"version": 1, "plugins": [
+			{"name": "heroku-npm", "version": "2.0.0", "integrity": "sha1-old", "pin": "^2.0.0"}
+		]}`), 0644)
+		lock, err := plugins.Lock()
+		Expect(err).ShouldNot(HaveOccurred())
+		locked := lock.Plugins.ByName("heroku-npm")
+		Expect(locked.Version).To(Equal("2.1.0"))
+		Expect(locked.Pin).To(Equal("^2.0.0"))
+		Expect(*locked.Previous).To(Equal(cli.LockedPlugin{Name: "heroku-npm", Version: "2.0.0", Integrity: "sha1-old"}))
+	})
+
+	It("changes the integrity of an executable plugin when its files change", func() {
+		before, _ := plugins.Lock()
+		ioutil.WriteFile(filepath.Join(executableDir, "bin", "xp"), []byte("#!/bin/sh\necho changed\n"), 0755)
+		after, _ := plugins.Lock()
+		Expect(after.Plugins.ByName("heroku-xp").Integrity).NotTo(Equal(before.Plugins.ByName("heroku-xp").Integrity))
+	})
+
+	Context("installing a locked plugin", func() {
+		var npmLog string
+		BeforeEach(func() {
+			npmLog, _ = filepath.Abs(filepath.Join("tmp", "plugins", "npm.log"))
+			node, _ := filepath.Abs(filepath.Join("tmp", "plugins", "node"))
+			ioutil.WriteFile(node, []byte(`#!/bin/sh
+shift
+case "$1" in
+view) echo '{"integrity": "sha512-cmVnaXN0cnk=", "shasum": "0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"}' ;;
+"") echo '{"name": "heroku-npm", "version": "2.1.0", "commands": [{"topic": "npm"}]}'; exit ;;
+esac
+echo "$@" >> `+npmLog+`
+`), 0755)
+			os.Setenv("HEROKU_NODE_PATH", node)
+		})
+		AfterEach(func() {
+			os.Unsetenv("HEROKU_NODE_PATH")
+		})
+
+		It("installs it when its integrity matches the registry", func() {
+			err := plugins.InstallLockedPlugin(cli.LockedPlugin{Name: "heroku-npm", Version: "2.1.0", Integrity: "sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM="})
+			Expect(err).ShouldNot(HaveOccurred())
+			log, _ := ioutil.ReadFile(npmLog)
+			Expect(string(log)).To(Equal("view heroku-npm@2.1.0 dist --json\ninstall heroku-npm@2.1.0\n"))
+		})
+
+		It("does not install it or touch the installed version when its integrity does not match", func() {
+			err := plugins.InstallLockedPlugin(cli.LockedPlugin{Name: "heroku-npm", Version: "2.1.0", Integrity: "sha512-b3RoZXI="})
+			Expect(err).To(MatchError("Integrity check failed for heroku-npm@2.1.0\nExpected sha512-b3RoZXI= but the registry has sha512-cmVnaXN0cnk= sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM="))
+			log, _ := ioutil.ReadFile(npmLog)
+			Expect(string(log)).To(Equal("view heroku-npm@2.1.0 dist --json\n"))
+			Expect(filepath.Join(npmDir, "package.json")).To(BeAnExistingFile())
+			Expect(plugins.ByName("heroku-npm")).NotTo(BeNil())
+		})
+	})
+})
+
+var _ = Describe("updating plugins", func() {
+	var plugins, userPlugins *cli.Plugins
+	var dir string
+	readLock := func() *cli.PluginLock {
+		var lock cli.PluginLock
+		data, _ := ioutil.ReadFile(filepath.Join(dir, "plugins.lock.json"))
+		must(json.Unmarshal(data, &lock))
+		return &lock
+	}
+	npmLog := func() string {
+		log, _ := ioutil.ReadFile(filepath.Join(dir, "npm.log"))
+		return string(log)
+	}
+	BeforeEach(func() {
+		dir, _ = filepath.Abs(filepath.Join("tmp", "plugins"))
+		plugins = &cli.Plugins{Path: dir}
+		for name, version := range map[string]string{"heroku-npm": "2.1.0", "heroku-other": "1.0.0"} {
+			os.MkdirAll(filepath.Join(dir, "node_modules", name), 0755)
+			ioutil.WriteFile(filepath.Join(dir, "node_modules", name, "package.json"), []byte(`{
+				"name": "`+name+`", "version": "`+version+`", "_shasum": "0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"
+			}`), 0644)
+		}
+		ioutil.WriteFile(filepath.Join(dir, "plugins.json"), []byte(`[
+			{"name": "heroku-npm", "version": "2.1.0", "commands": [{"topic": "npm"}]},
+			{"name": "heroku-other", "version": "1.0.0", "commands": [{"topic": "other"}]}
+		]`), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "plugins.lock.json"), []byte(`{"version": 1, "plugins": [
+			{"name": "heroku-npm", "version": "2.1.0", "integrity": "sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM=", "pin": "^2.1.0"},
+			{"name": "heroku-other", "version": "1.0.0", "integrity": "sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM="}
+		]}`), 0644)
+		// a fake node that runs a fake npm for the registry and installs, and parses the last installed plugin
+		ioutil.WriteFile(filepath.Join(dir, "node"), []byte(`#!/bin/sh
+shift
+dir=`+dir+`
+if [ -z "$1" ]; then cat $dir/installed; exit; fi
+echo "$@" >> $dir/npm.log
+case "$1" in
+view)
+	case "$2 $3" in
+	"heroku-npm@^2.1.0 version") echo '["2.1.0", "2.1.5"]' ;;
+	"heroku-npm@^3.0.0 version") echo '"3.0.0"' ;;
+	*" dist") echo '{"shasum": "0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"}' ;;
+	esac ;;
+outdated) echo '{"heroku-other": {"current": "1.0.0", "latest": "1.5.0"}}' ;;
+install)
+	name=${2%@*}
+	version=${2##*@}
+	echo "{\"name\": \"$name\", \"version\": \"$version\", \"_shasum\": \"0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33\"}" > $dir/node_modules/$name/package.json
+	echo "{\"name\": \"$name\", \"version\": \"$version\", \"commands\": [{\"topic\": \"npm\"}]}" > $dir/installed ;;
+esac
+`), 0755)
+		os.Setenv("HEROKU_NODE_PATH", filepath.Join(dir, "node"))
+		userPlugins = cli.UserPlugins
+		cli.UserPlugins = plugins
+	})
+	AfterEach(func() {
+		cli.UserPlugins = userPlugins
+		os.Unsetenv("HEROKU_NODE_PATH")
+		os.RemoveAll(dir)
+	})
+
+	It("updates pinned plugins within their pin and the others to the latest version", func() {
+		plugins.Update()
+		Expect(npmLog()).To(ContainSubstring("view heroku-npm@^2.1.0 version --json\n"))
+		Expect(npmLog()).To(ContainSubstring("outdated --json heroku-other\n"))
+		Expect(npmLog()).To(ContainSubstring("install heroku-npm@2.1.5\n"))
+		Expect(npmLog()).To(ContainSubstring("install heroku-other@1.5.0\n"))
+		locked := readLock().Plugins.ByName("heroku-npm")
+		Expect(locked.Version).To(Equal("2.1.5"))
+		Expect(locked.Pin).To(Equal("^2.1.0"))
+		Expect(locked.Previous.Version).To(Equal("2.1.0"))
+	})
+
+	It("moves the pin with plugins:update --to and rolls back to the previous version", func() {
+		cli.Start("heroku", "plugins:update", "heroku-npm", "--to", "^3.0.0")
+		Expect(npmLog()).To(ContainSubstring("install heroku-npm@3.0.0\n"))
+		locked := readLock().Plugins.ByName("heroku-npm")
+		Expect(locked.Version).To(Equal("3.0.0"))
+		Expect(locked.Pin).To(Equal("^3.0.0"))
+		Expect(locked.Previous.Version).To(Equal("2.1.0"))
+
+		cli.Start("heroku", "plugins:rollback", "heroku-npm")
+		Expect(npmLog()).To(HaveSuffix("view heroku-npm@2.1.0 dist --json\ninstall heroku-npm@2.1.0\n"))
+		locked = readLock().Plugins.ByName("heroku-npm")
+		Expect(locked.Version).To(Equal("2.1.0"))
+		Expect(locked.Pin).To(Equal("2.1.0"))
+		Expect(locked.Previous.Version).To(Equal("3.0.0"))
+		Expect(stderr()).To(ContainSubstring("heroku-npm is pinned to 2.1.0."))
+	})
+})
+
+var _ = Describe("plugin conflicts", func() {
+	var topicBackup cli.Topics
+	var userPlugins *cli.Plugins
+	var configHome string
+	var ran string
+	conflictsCache := filepath.Join("tmp", "plugins", "conflicts.json")
+	BeforeEach(func() {
+		topicBackup = cli.CLITopics
+		configHome = cli.ConfigHome
+		cli.ConfigHome = filepath.Join("tmp", "config")
+		os.MkdirAll(cli.ConfigHome, 0755)
+		userPlugins = cli.UserPlugins
+		cli.UserPlugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		os.MkdirAll(cli.UserPlugins.Path, 0755)
+		cli.CLITopics = append(cli.CLITopics, &cli.Topic{
+			Name: "dup",
+			Commands: cli.Commands{
+				{Topic: "dup", Plugin: "heroku-a", Run: func(ctx *cli.Context) { ran = "heroku-a" }},
+				{Topic: "dup", Plugin: "heroku-b", Run: func(ctx *cli.Context) { ran = "heroku-b" }},
+			},
+		})
+	})
+	AfterEach(func() {
+		cli.Start("heroku", "plugins:prefer", "dup")
+		cli.CLITopics = topicBackup
+		os.RemoveAll(cli.ConfigHome)
+		cli.ConfigHome = configHome
+		os.RemoveAll(cli.UserPlugins.Path)
+		cli.UserPlugins = userPlugins
+	})
+
+	It("lists commands in more than one plugin", func() {
+		cli.Start("heroku", "plugins:conflicts")
+		Expect(stdout()).To(Equal("=== dup\nheroku-a (runs)\nheroku-b\n\n"))
+	})
+
+	It("warns with both plugin names when running a conflicting command", func() {
+		cli.Start("heroku", "dup")
+		Expect(ran).To(Equal("heroku-a"))
+		Expect(stderr()).To(HavePrefix(" !    heroku dup is a command in heroku-a and heroku-b. Running the one in heroku-a.\n"))
+	})
+
+	It("caches the conflicting command names", func() {
+		cli.Start("heroku", "dup")
+		data, err := ioutil.ReadFile(conflictsCache)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"commands": [
+    "dup"
+  ]`))
+	})
+
+	It("only compares commands again when the cache is missing or from another version", func() {
+		ioutil.WriteFile(conflictsCache, []byte(`{"version": "`+cli.Version+`", "commands": []}`), 0644)
+		cli.Start("heroku", "dup")
+		Expect(stderr()).To(BeEmpty())
+		ioutil.WriteFile(conflictsCache, []byte(`{"version": "0.0.0", "commands": []}`), 0644)
+		cli.Start("heroku", "dup")
+		Expect(stderr()).To(ContainSubstring("heroku dup is a command in heroku-a and heroku-b."))
+	})
+
+	It("removes the cache when the plugin cache changes", func() {
+		cli.Start("heroku", "dup")
+		Expect(conflictsCache).To(BeAnExistingFile())
+		dir := filepath.Join(cli.UserPlugins.Path, "executables", "heroku-xp")
+		os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "bin", "xp"), []byte("#!/bin/sh\n"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp", "commands": [{"topic": "xp"}]
+		}`), 0644)
+		_, err := cli.UserPlugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = os.Stat(conflictsCache)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("runs the preferred plugin without a warning", func() {
+		cli.Start("heroku", "plugins:prefer", "dup", "heroku-b")
+		Expect(stdout()).To(Equal("heroku dup now runs the command in heroku-b\n"))
+		cli.Start("heroku", "dup")
+		Expect(ran).To(Equal("heroku-b"))
+		Expect(stderr()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("plugin info", func() {
+	var plugins *cli.Plugins
+	var pluginDir string
+	BeforeEach(func() {
+		plugins = &cli.Plugins{Path: filepath.Join("tmp", "plugins")}
+		pluginDir, _ = filepath.Abs(filepath.Join("tmp", "heroku-xp"))
+		os.MkdirAll(filepath.Join(pluginDir, "bin"), 0755)
+		ioutil.WriteFile(filepath.Join(pluginDir, "bin", "xp"), []byte("#!/bin/sh\n"), 0755)
+		ioutil.WriteFile(filepath.Join(pluginDir, "heroku-plugin.json"), []byte(`{
+			"name": "heroku-xp", "version": "1.0.0", "executable": "bin/xp",
+			"topics": [{"name": "xp"}],
+			"commands": [{"topic": "xp", "command": "hello"}, {"topic": "yp"}]
+		}`), 0644)
+	})
+	AfterEach(func() {
+		os.RemoveAll(filepath.Join("tmp", "plugins"))
+		os.RemoveAll(pluginDir)
+	})
+
+	It("shows the topics and commands of a plugin", func() {
+		os.MkdirAll(filepath.Join("tmp", "plugins", "executables"), 0755)
+		os.Rename(pluginDir, filepath.Join("tmp", "plugins", "executables", "heroku-xp"))
+		_, err := plugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).ShouldNot(HaveOccurred())
+		info := plugins.Info("heroku-xp", false)
+		Expect(info.Source).To(Equal("user"))
+		Expect(info.Path).To(HaveSuffix(filepath.Join("tmp", "plugins", "executables", "heroku-xp")))
+		Expect(info.SymlinkTarget).To(BeEmpty())
+		Expect(info.Topics).To(Equal([]string{"xp", "yp"}))
+		Expect(info.Commands).To(Equal([]string{"xp:hello", "yp"}))
+		Expect(info.Outdated).To(BeNil())
+		Expect(info.UpdatedAt.IsZero()).To(BeFalse())
+		Expect(*plugins.Info("heroku-xp", true).Outdated).To(BeFalse())
+	})
+
+	It("shows where a linked plugin points", func() {
+		os.MkdirAll(filepath.Join("tmp", "plugins", "executables"), 0755)
+		os.Symlink(pluginDir, filepath.Join("tmp", "plugins", "executables", "heroku-xp"))
+		_, err := plugins.ParseExecutablePlugin("heroku-xp")
+		Expect(err).ShouldNot(HaveOccurred())
+		info := plugins.Info("heroku-xp", false)
+		Expect(info.Source).To(Equal("linked"))
+		target, _ := filepath.EvalSymlinks(pluginDir)
+		Expect(info.SymlinkTarget).To(Equal(target))
+	})
+
+	It("is nil for plugins that are not installed", func() {
+		Expect(plugins.Info("heroku-missing", false)).To(BeNil())
+	})
+
+	Context("with an npm plugin", func() {
+		var userPlugins *cli.Plugins
+		var npmLog string
+		BeforeEach(func() {
+			npmDir := filepath.Join("tmp", "plugins", "node_modules", "heroku-npm")
+			os.MkdirAll(npmDir, 0755)
+			ioutil.WriteFile(filepath.Join(npmDir, "package.json"), []byte(`{"name": "heroku-npm", "version": "2.1.0"}`), 0644)
+			ioutil.WriteFile(filepath.Join("tmp", "plugins", "plugins.json"), []byte(`[
+				{"name": "heroku-npm", "version": "2.1.0", "commands": [{"topic": "npm"}]}
+			]`), 0644)
+			npmLog, _ = filepath.Abs(filepath.Join("tmp", "plugins", "npm.log"))
+			node, _ := filepath.Abs(filepath.Join("tmp", "plugins", "node"))
+			ioutil.WriteFile(node, []byte(`#!/bin/sh
+shift
+echo "$@" >> `+npmLog+`
+echo '{"heroku-npm": {"current": "2.1.0", "latest": "2.2.0"}}'
+`), 0755)
+			os.Setenv("HEROKU_NODE_PATH", node)
+			userPlugins = cli.UserPlugins
+			cli.UserPlugins = plugins
+		})
+		AfterEach(func() {
+			cli.UserPlugins = userPlugins
+			os.Unsetenv("HEROKU_NODE_PATH")
+		})
+
+		It("lists plugins as json without checking the registry", func() {
+			cli.Start("heroku", "plugins", "--json")
+			Expect(stdout()).To(ContainSubstring(`"name": "heroku-npm"`))
+			Expect(stdout()).NotTo(ContainSubstring(`"outdated"`))
+			Expect(npmLog).NotTo(BeAnExistingFile())
+		})
+
+		It("checks the registry with --outdated", func() {
+			cli.Start("heroku", "plugins", "--json", "--outdated")
+			Expect(stdout()).To(ContainSubstring(`"outdated": true,
+    "latest": "2.2.0"`))
+			log, _ := ioutil.ReadFile(npmLog)
+			Expect(string(log)).To(Equal("outdated --json heroku-npm\n"))
+		})
+	})
+})
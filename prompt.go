@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/dickeyxxx/speakeasy"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Stdin is used to mock stdin for testing
+var Stdin = bufio.NewReader(os.Stdin)
+
+// Interactive is true when missing arguments and flags can be prompted for.
+// It is used to mock a terminal for testing.
+var Interactive = func() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// promptLine asks for a value until a non-empty one is entered.
+// Secret values are not echoed.
+func promptLine(prompt string, secret bool) (string, error) {
+	for {
+		var value string
+		var err error
+		if secret {
+			value, err = speakeasy.FAsk(os.Stderr, prompt)
+		} else {
+			Err(prompt)
+			value, err = Stdin.ReadString('\n')
+			if err != nil && value != "" {
+				err = nil
+			}
+		}
+		if err != nil {
+			Errln()
+			return "", err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return value, nil
+		}
+	}
+}
+
+// promptFlag asks for the value of a flag, asking again if the value is not valid for its type
+func promptFlag(flag *Flag) (interface{}, error) {
+	prompt := flag.flagName()
+	if flag.Description != "" {
+		prompt += " (" + flag.Description + ")"
+	}
+	for {
+		input, err := promptLine(prompt+": ", flag.Secret)
+		if err != nil {
+			return nil, err
+		}
+		value, err := flag.ParseValue(input)
+		if err != nil {
+			Warn(err.Error())
+			continue
+		}
+		return value, nil
+	}
+}
+
+func promptArg(arg Arg) (string, error) {
+	return promptLine(strings.ToUpper(arg.Name)+": ", false)
+}
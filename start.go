@@ -29,12 +29,15 @@ func Start(args ...string) {
 	Args = args
 	loadNewCLI()
 
+	unexpandedArgs = Args
+
 	ShowDebugInfo()
 
 	if len(Args) <= 1 {
 		// show dashboard if no args passed
 		Args = append(Args, "dashboard")
 	}
+	Args = append(Args[:1], expandUserAlias(Args[1:])...)
 
 	switch Args[1] {
 	case "_":
@@ -50,26 +53,60 @@ func Start(args ...string) {
 		return
 	}
 
-	for _, arg := range Args {
-		if arg == "--help" || arg == "-h" {
-			help()
-			return
-		}
+	expanded, err := expandArgFiles(Args[2:])
+	if err != nil {
+		exitWithError(err)
+		return
 	}
+	Args = append(Args[:2:2], expanded...)
 
-	cmd := AllCommands().Find(Args[1])
+	if helpRequested(Args) {
+		help()
+		return
+	}
+
+	cmd := findCommand(Args[1])
 	if cmd == nil {
+		if path := findExternalCommand(Args[1]); path != "" {
+			runExternalCommand(path, Args[1:])
+			return
+		}
 		helpInvalidCommand()
 		return
 	}
+	warnIfConflicted(Args[1])
 	if !cmd.DisableAnalytics {
 		currentAnalyticsCommand.RecordStart()
 	}
 	ctx, err := BuildContext(cmd, Args)
-	must(err)
+	if err != nil {
+		exitWithError(err)
+		return
+	}
+	if len(ctx.Apps) > 1 {
+		runForApps(ctx.Apps)
+		return
+	}
 	cmd.Run(ctx)
 }
 
+// helpRequested is true if --help or -h is given before -- or,
+// for commands with FlagsBeforeArgs, before the first argument
+func helpRequested(args []string) bool {
+	cmd := findCommand(args[1])
+	for _, arg := range args[2:] {
+		switch {
+		case arg == "--":
+			return false
+		case arg == "--help" || arg == "-h":
+			return true
+		case cmd != nil && cmd.FlagsBeforeArgs && !strings.HasPrefix(arg, "-"):
+			return false
+		}
+	}
+	return false
+}
+
 var crashing = false
 
 // ShowDebugInfo prints debugging information if HEROKU_DEBUG=1
@@ -5,9 +5,10 @@ import "sort"
 // Topic represents a CLI topic.
 // For example, in the command `heroku apps:create` the topic would be `apps`.
 type Topic struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Hidden      bool   `json:"hidden"`
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description"`
+	Hidden      bool     `json:"hidden"`
 	Commands    []*Command
 }
 
@@ -28,6 +29,16 @@ func (topics Topics) ByName(name string) *Topic {
 	return nil
 }
 
+// ByAlias returns a topic in the set with a matching alias.
+func (topics Topics) ByAlias(alias string) *Topic {
+	for _, topic := range topics {
+		if contains(topic.Aliases, alias) {
+			return topic
+		}
+	}
+	return nil
+}
+
 // Concat joins 2 topic sets together
 func (topics Topics) Concat(more Topics) Topics {
 	for _, topic := range more {
@@ -10,7 +10,7 @@ func init() {
 				Args:  []Arg{{Name: "command"}},
 				Run: func(ctx *Context) {
 					command := ctx.Args.(map[string]string)["command"]
-					cmd := AllCommands().Find(command)
+					cmd := findCommand(command)
 					if cmd == nil {
 						Println("No command found. Could be a ruby command. https://github.com/heroku/heroku")
 						Exit(1)